@@ -4,13 +4,12 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"image/draw"
+	"io"
 	"math"
 
 	"github.com/golang/freetype/truetype"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
-	"golang.org/x/image/math/fixed"
 )
 
 // Context provides simple methods for drawing over an image.
@@ -22,11 +21,42 @@ type Context struct {
 	fontDrawer  *font.Drawer
 	font        *truetype.Font
 	fontOptions *truetype.Options
+
+	// renderer is the pluggable backend that the unified path subsystem
+	// (MoveTo, LineTo, QuadraticTo, CubicTo, ArcTo, ClosePath, Fill,
+	// Stroke, FillStroke) and Text draw through. See NewContext and
+	// NewSVGContext.
+	renderer Renderer
+
+	// Path state, built up by MoveTo/LineTo/QuadraticTo/CubicTo/ArcTo/
+	// ClosePath and consumed by Fill, Stroke and FillStroke.
+	subpaths   []subpath
+	current    pathPoint
+	pathStart  pathPoint
+	hasCurrent bool
+
+	// Fill and stroke styling applied by Fill, Stroke and FillStroke.
+	fillRule   WindingRule
+	lineWidth  float64
+	lineCap    LineCap
+	lineJoin   LineJoin
+	miterLimit float64
+	dash       Dash
+
+	// antialias enables coverage-based antialiasing in Line and Polygon.
+	antialias bool
+
+	// transform is the current coordinate transform, applied by the
+	// Float64 counterparts of the coordinate-accepting drawing methods.
+	// transformStack holds the matrices saved by Push, restored by Pop.
+	transform      Matrix
+	transformStack []Matrix
 }
 
-// NewContext creates a new context for drawing over image.
+// NewContext creates a new context for drawing over image, rasterizing
+// every primitive directly into rgba.
 func NewContext(rgba *image.RGBA) *Context {
-	return &Context{
+	c := &Context{
 		rgba:      rgba,
 		penColor:  color.Black,
 		fillColor: color.Transparent,
@@ -36,17 +66,51 @@ func NewContext(rgba *image.RGBA) *Context {
 			Src:  image.NewUniform(color.Black),
 			Face: basicfont.Face7x13,
 		},
+		fontOptions: &truetype.Options{},
+		lineWidth:   1,
+		miterLimit:  10,
+		transform:   identityMatrix(),
+	}
+	c.renderer = &rasterRenderer{ctx: c}
+	return c
+}
+
+// NewSVGContext creates a new context of the given pixel dimensions that
+// writes SVG markup to w instead of rasterizing into an image.RGBA. The
+// same drawing methods as NewContext are available, but only those that
+// go through the unified path subsystem (MoveTo, LineTo, QuadraticTo,
+// CubicTo, ArcTo, ClosePath, Fill, Stroke, FillStroke) and Text are
+// backed by the SVG renderer; the lower-level pixel methods (Dot, Line,
+// Polygon, Parabola and friends) have no image.RGBA to draw into and
+// must not be called on a context created this way. Call Finalize once
+// drawing is done to write the closing </svg> tag.
+func NewSVGContext(w io.Writer, width, height int) *Context {
+	c := &Context{
+		penColor:  color.Black,
+		fillColor: color.Transparent,
+		textColor: color.Black,
+		fontDrawer: &font.Drawer{
+			Face: basicfont.Face7x13,
+		},
+		fontOptions: &truetype.Options{},
+		lineWidth:   1,
+		miterLimit:  10,
+		transform:   identityMatrix(),
 	}
+	c.renderer = newSVGRenderer(c, w, width, height)
+	return c
 }
 
 // SetPen changes the pen color (outline color).
 func (c *Context) SetPen(clr color.Color) {
 	c.penColor = clr
+	c.renderer.SetPen(clr)
 }
 
 // SetFill changes the fill color.
 func (c *Context) SetFill(clr color.Color) {
 	c.fillColor = clr
+	c.renderer.SetFill(clr)
 }
 
 // SetFontFace changes the font face and font options.
@@ -54,6 +118,7 @@ func (c *Context) SetFontFace(font *truetype.Font, options *truetype.Options) {
 	c.font = font
 	*c.fontOptions = *options
 	c.fontDrawer.Face = truetype.NewFace(font, c.fontOptions)
+	c.renderer.SetFontFace(c.fontDrawer.Face)
 }
 
 // SetFontSize changes the font size only.
@@ -69,11 +134,35 @@ func (c *Context) SetTextColor(clr color.Color) {
 
 // Dot draw a single dot at x,y coordinates.
 func (c *Context) Dot(x, y int) {
+	c.DotF(float64(x), float64(y))
+}
+
+// DotF is the Float64 counterpart of Dot: it draws a single dot at x,y
+// after applying the current transform (see Translate, Scale, Rotate).
+func (c *Context) DotF(x, y float64) {
+	tx, ty := c.transform.Transform(x, y)
+	c.dotRaw(int(math.Round(tx)), int(math.Round(ty)))
+}
+
+// dotRaw sets a pixel directly in device space, bypassing the transform.
+func (c *Context) dotRaw(x, y int) {
 	c.rgba.Set(x, y, c.penColor)
 }
 
 // FillPixel fills the pixel at x,y with the current fill color.
 func (c *Context) FillPixel(x, y int) {
+	c.FillPixelF(float64(x), float64(y))
+}
+
+// FillPixelF is the Float64 counterpart of FillPixel: it fills the pixel at
+// x,y after applying the current transform.
+func (c *Context) FillPixelF(x, y float64) {
+	tx, ty := c.transform.Transform(x, y)
+	c.fillPixelRaw(int(math.Round(tx)), int(math.Round(ty)))
+}
+
+// fillPixelRaw sets a pixel directly in device space, bypassing the transform.
+func (c *Context) fillPixelRaw(x, y int) {
 	c.rgba.Set(x, y, c.fillColor)
 }
 
@@ -84,8 +173,35 @@ func (c *Context) Dots(points []image.Point) {
 	}
 }
 
-// Line draws an approximation of a straight line between two points using Bresenham's algorithm.
+// Line draws a straight line between two points. By default this uses
+// Bresenham's algorithm; when Antialias is enabled (see SetAntialias) it
+// uses Xiaolin Wu's algorithm instead, blending the pen color into the
+// destination with fractional coverage to avoid staircase artifacts.
 func (c *Context) Line(x0, y0, x1, y1 int) {
+	c.LineF(float64(x0), float64(y0), float64(x1), float64(y1))
+}
+
+// LineF is the Float64 counterpart of Line: it draws a straight line
+// between two points after applying the current transform.
+func (c *Context) LineF(x0, y0, x1, y1 float64) {
+	tx0, ty0 := c.transform.Transform(x0, y0)
+	tx1, ty1 := c.transform.Transform(x1, y1)
+	c.lineRaw(tx0, ty0, tx1, ty1)
+}
+
+// lineRaw draws a straight line between two points already in device
+// space, bypassing the transform.
+func (c *Context) lineRaw(x0, y0, x1, y1 float64) {
+	if c.antialias {
+		c.lineWu(x0, y0, x1, y1)
+		return
+	}
+	c.lineBresenham(int(math.Round(x0)), int(math.Round(y0)), int(math.Round(x1)), int(math.Round(y1)))
+}
+
+// lineBresenham draws an approximation of a straight line between two
+// points already in device space, using Bresenham's algorithm.
+func (c *Context) lineBresenham(x0, y0, x1, y1 int) {
 	swap0and1 := false
 	swapXandY := math.Abs(float64(y1-y0)) >= math.Abs(float64(x1-x0))
 	if swapXandY && y0 > y1 {
@@ -118,9 +234,9 @@ func (c *Context) Line(x0, y0, x1, y1 int) {
 
 	for x := x0; x < x1; x++ {
 		if swapXandY {
-			c.Dot(y, x)
+			c.dotRaw(y, x)
 		} else {
-			c.Dot(x, y)
+			c.dotRaw(x, y)
 		}
 
 		if D > 0 {
@@ -135,15 +251,14 @@ func (c *Context) Line(x0, y0, x1, y1 int) {
 
 // Rect draws a rectangle with pen's color.
 func (c *Context) Rect(x0, y0, x1, y1 int) {
-	if c.penColor != color.Transparent {
-		c.Line(x0, y0, x1, y0)
-		c.Line(x1, y0, x1, y1)
-		c.Line(x1, y1, x0, y1)
-		c.Line(x0, y0, x0, y1)
-	}
-	if c.fillColor != color.Transparent {
-		draw.Draw(c.rgba, image.Rect(x0, y0, x1, y0), &image.Uniform{c.fillColor}, image.ZP, draw.Src)
-	}
+	c.RectF(float64(x0), float64(y0), float64(x1), float64(y1))
+}
+
+// RectF is the Float64 counterpart of Rect: it draws a rectangle with the
+// pen's color, as a four-point Polygon so that rotation and shear turn it
+// into the expected parallelogram instead of an axis-aligned box.
+func (c *Context) RectF(x0, y0, x1, y1 float64) {
+	c.PolygonF([]PointF{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}})
 }
 
 // Cross draws a cross centered at x,y.
@@ -163,6 +278,18 @@ func (c *Context) Path(points []image.Point) {
 	}
 }
 
+// pathRaw draws a sequence of points, connected by lines, already in
+// device space, bypassing the transform.
+func (c *Context) pathRaw(points []image.Point) {
+	var last image.Point
+	for i, point := range points {
+		if i > 0 {
+			c.lineRaw(float64(last.X), float64(last.Y), float64(point.X), float64(point.Y))
+		}
+		last = point
+	}
+}
+
 // IsInPolygon tests if the point at X and Y lies inside the polygon defined by the given points.
 func (c *Context) IsInPolygon(x, y int, points []image.Point) bool {
 	// Custom point type with floating point coordinate values
@@ -211,46 +338,54 @@ func maxInt(a, b int) int {
 
 // Polygon outlines and fills a polygon defined by the given points.
 func (c *Context) Polygon(points []image.Point) {
-	// Remove duplicate points
-	p := make([]image.Point, 0)
+	pts := make([]PointF, len(points))
+	for i, pnt := range points {
+		pts[i] = PointF{X: float64(pnt.X), Y: float64(pnt.Y)}
+	}
+	c.PolygonF(pts)
+}
+
+// PolygonF is the Float64 counterpart of Polygon: it outlines and fills a
+// polygon defined by the given points, after applying the current
+// transform to each of them.
+func (c *Context) PolygonF(points []PointF) {
+	// Apply the transform and remove duplicate points
+	p := make([]image.Point, 0, len(points))
 	exists := make(map[string]bool)
 	for _, pnt := range points {
-		id := fmt.Sprintf("%d,%d", pnt.X, pnt.Y)
+		tx, ty := c.transform.Transform(pnt.X, pnt.Y)
+		ip := image.Point{X: int(math.Round(tx)), Y: int(math.Round(ty))}
+		id := fmt.Sprintf("%d,%d", ip.X, ip.Y)
 		if !exists[id] {
 			exists[id] = true
-			p = append(p, pnt)
+			p = append(p, ip)
 		}
 	}
 
-	// Determine the bounding box of the polygon
-	img := c.rgba.Bounds()
-	minX, maxX := img.Max.X, img.Min.X
-	minY, maxY := img.Max.Y, img.Min.Y
-	for i := 0; i < len(p); i++ {
-		minX = minInt(p[i].X, minX)
-		maxX = minInt(p[i].X, maxX)
-		minY = minInt(p[i].Y, minY)
-		maxY = minInt(p[i].Y, maxY)
-	}
-	// Make sure X and Y are inside the bounds of the image
-	minX = minInt(minX, img.Min.X)
-	minY = minInt(minY, img.Min.Y)
-	maxX = maxInt(maxX, img.Max.X)
-	maxY = maxInt(maxY, img.Max.Y)
+	// Determine the bounding box of the polygon, clipped to the image
+	contours := [][]image.Point{p}
+	bounds := contoursBounds(contours, c.rgba.Bounds())
 
-	// Draw a path with outline color
+	// Draw a path with outline color. The points are already in device
+	// space, so this bypasses the transform rather than applying it twice.
 	if c.penColor != color.Transparent {
-		c.Path(p)
+		c.pathRaw(p)
 	}
 
-	// Fill pixels that lie inside the polygon
+	// Fill pixels that lie inside the polygon using an active-edge-table
+	// scanline sweep, honoring the context's fill rule. This is linear in
+	// the number of edge crossings per scanline, rather than the
+	// O(width*height*n) cost of testing every pixel in the bounding box
+	// against every edge.
 	if c.fillColor != color.Transparent {
-		for y := minY; y < maxY; y++ {
-			for x := minX; x < maxX; x++ {
-				if c.IsInPolygon(x, y, p) {
-					c.FillPixel(x, y)
-				}
-			}
+		if c.antialias {
+			fillScanline(contours, bounds, c.fillRule, true, func(x, y int, coverage float64) {
+				c.blendPixel(x, y, c.fillColor, coverage)
+			})
+		} else {
+			fillScanline(contours, bounds, c.fillRule, false, func(x, y int, coverage float64) {
+				c.fillPixelRaw(x, y)
+			})
 		}
 	}
 }
@@ -281,12 +416,15 @@ func (c *Context) ParabolaArc(a1, b1, c1 float64, x1, x2 int) {
 // Text draws the given text at x,y with the font chosen in context.
 // The default font is golang.org/x/image/font/basicfont.
 func (c *Context) Text(x, y int, text string) {
-	point := fixed.Point26_6{
-		X: fixed.Int26_6(x * 64),
-		Y: fixed.Int26_6(y * 64),
-	}
+	c.TextF(float64(x), float64(y), text)
+}
 
-	c.fontDrawer.Src = image.NewUniform(c.textColor)
-	c.fontDrawer.Dot = point
-	c.fontDrawer.DrawString(text)
+// TextF is the Float64 counterpart of Text: it draws the given text with
+// its baseline origin at x,y after applying the current transform. Scale,
+// Rotate and Shear affect the glyphs themselves, not just their origin -
+// see rasterRenderer.Text for how the raster backend achieves this.
+// Drawing itself is delegated to the context's Renderer (see NewContext
+// and NewSVGContext).
+func (c *Context) TextF(x, y float64, text string) {
+	c.renderer.Text(x, y, text, c.fontDrawer.Face, c.textColor)
 }
@@ -0,0 +1,732 @@
+package draw
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// flattenTolerance is the maximum allowed deviation, in pixels, between a
+// curve and the line segments used to approximate it.
+const flattenTolerance = 0.5
+
+// WindingRule determines which pixels count as "inside" a path when filling
+// subpaths that overlap or self-intersect.
+type WindingRule int
+
+// Supported winding rules for Fill and FillStroke.
+const (
+	// EvenOdd considers a point inside the path if a ray from it crosses
+	// the path's edges an odd number of times.
+	EvenOdd WindingRule = iota
+	// NonZero considers a point inside the path if the signed count of
+	// edges crossed by a ray from it is non-zero.
+	NonZero
+)
+
+// LineCap determines how the ends of an open subpath are drawn when stroking.
+type LineCap int
+
+// Supported line caps for Stroke and FillStroke.
+const (
+	CapButt LineCap = iota
+	CapRound
+	CapSquare
+)
+
+// LineJoin determines how two adjacent stroked segments are joined at a vertex.
+type LineJoin int
+
+// Supported line joins for Stroke and FillStroke.
+const (
+	JoinMiter LineJoin = iota
+	JoinRound
+	JoinBevel
+)
+
+// Dash describes the on/off pattern applied to a path while stroking it.
+// Pattern holds alternating on/off lengths in pixels and Phase offsets where
+// the pattern starts along the path.
+type Dash struct {
+	Pattern []float64
+	Phase   float64
+}
+
+// pathPoint is a point on a path, kept in floating point since curves and
+// arcs rarely land on whole pixels. It is an alias for PointF, the public
+// point type used by the Float64 counterparts of Context's drawing methods.
+type pathPoint = PointF
+
+// subpath is a single contiguous run of the path, already flattened to line
+// segments by the Move/Line/Quadratic/Cubic/Arc family of methods.
+type subpath struct {
+	points []pathPoint
+	closed bool
+}
+
+// resetPath discards any path segments accumulated so far.
+func (c *Context) resetPath() {
+	c.subpaths = nil
+	c.hasCurrent = false
+}
+
+// MoveTo starts a new subpath at x,y without drawing anything. Like the
+// legacy DotF/LineF/PolygonF/TextF methods, x,y is subject to the current
+// transform (see Translate, Scale, Rotate, Shear).
+func (c *Context) MoveTo(x, y float64) {
+	tx, ty := c.transform.Transform(x, y)
+	c.subpaths = append(c.subpaths, subpath{points: []pathPoint{{tx, ty}}})
+	c.current = pathPoint{tx, ty}
+	c.pathStart = c.current
+	c.hasCurrent = true
+	c.renderer.MoveTo(tx, ty)
+}
+
+// LineTo appends a straight line segment from the current point to x,y,
+// subject to the current transform.
+func (c *Context) LineTo(x, y float64) {
+	if !c.hasCurrent {
+		c.MoveTo(x, y)
+		return
+	}
+	tx, ty := c.transform.Transform(x, y)
+	c.appendPoint(pathPoint{tx, ty})
+	c.renderer.LineTo(tx, ty)
+}
+
+// QuadraticTo appends a quadratic Bézier curve from the current point to
+// x,y using cx,cy as the control point, subject to the current transform.
+// Since an affine transform of a Bézier curve's control points produces the
+// same curve transformed, the curve is flattened and forwarded to the
+// renderer entirely in the already-transformed coordinate space.
+func (c *Context) QuadraticTo(cx, cy, x, y float64) {
+	if !c.hasCurrent {
+		c.MoveTo(x, y)
+		return
+	}
+	tcx, tcy := c.transform.Transform(cx, cy)
+	tx, ty := c.transform.Transform(x, y)
+	start := c.current
+	end := pathPoint{tx, ty}
+	for _, p := range flattenQuadratic(start, pathPoint{tcx, tcy}, end) {
+		c.appendPoint(p)
+	}
+
+	// The Renderer interface only has a cubic Curve primitive, so elevate
+	// the quadratic to the cubic with the same shape: control points at
+	// 2/3 of the way from each endpoint to the quadratic control point.
+	cx1, cy1 := start.X+2.0/3.0*(tcx-start.X), start.Y+2.0/3.0*(tcy-start.Y)
+	cx2, cy2 := tx+2.0/3.0*(tcx-tx), ty+2.0/3.0*(tcy-ty)
+	c.renderer.Curve(cx1, cy1, cx2, cy2, tx, ty)
+}
+
+// CubicTo appends a cubic Bézier curve from the current point to x,y using
+// cx1,cy1 and cx2,cy2 as the control points, subject to the current
+// transform (see QuadraticTo for why transforming the control points is
+// equivalent to transforming the curve).
+func (c *Context) CubicTo(cx1, cy1, cx2, cy2, x, y float64) {
+	if !c.hasCurrent {
+		c.MoveTo(x, y)
+		return
+	}
+	tcx1, tcy1 := c.transform.Transform(cx1, cy1)
+	tcx2, tcy2 := c.transform.Transform(cx2, cy2)
+	tx, ty := c.transform.Transform(x, y)
+	end := pathPoint{tx, ty}
+	for _, p := range flattenCubic(c.current, pathPoint{tcx1, tcy1}, pathPoint{tcx2, tcy2}, end) {
+		c.appendPoint(p)
+	}
+	c.renderer.Curve(tcx1, tcy1, tcx2, tcy2, tx, ty)
+}
+
+// ArcTo appends an elliptical arc centered at cx,cy with radii rx,ry,
+// starting at startAngle radians and sweeping sweepAngle radians (positive
+// sweeps clockwise, as Y grows downward). A line is drawn from the current
+// point to the arc's starting point before the arc itself. The arc is built
+// from points on the ellipse in the untransformed user space, then each
+// point - including Bézier control points - is run through the current
+// transform before being recorded, the same way QuadraticTo/CubicTo do.
+func (c *Context) ArcTo(cx, cy, rx, ry, startAngle, sweepAngle float64) {
+	start := ellipsePoint(cx, cy, rx, ry, startAngle)
+	tstart := c.transformPoint(start)
+	if !c.hasCurrent {
+		c.MoveTo(start.X, start.Y)
+	} else {
+		c.appendPoint(tstart)
+		c.renderer.LineTo(tstart.X, tstart.Y)
+	}
+
+	// Approximate the arc with a chain of cubic Béziers, each spanning at
+	// most a quarter turn, using the standard k = 4/3*tan(θ/4) control
+	// point offset.
+	remaining := sweepAngle
+	angle := startAngle
+	const maxSweep = math.Pi / 2
+	for remaining != 0 {
+		step := maxSweep
+		if step > math.Abs(remaining) {
+			step = math.Abs(remaining)
+		}
+		if remaining < 0 {
+			step = -step
+		}
+
+		p0 := ellipsePoint(cx, cy, rx, ry, angle)
+		p3 := ellipsePoint(cx, cy, rx, ry, angle+step)
+		k := 4.0 / 3.0 * math.Tan(step/4)
+
+		t0 := ellipseTangent(rx, ry, angle)
+		t3 := ellipseTangent(rx, ry, angle+step)
+		p1 := pathPoint{p0.X + k*t0.X, p0.Y + k*t0.Y}
+		p2 := pathPoint{p3.X - k*t3.X, p3.Y - k*t3.Y}
+
+		tp1 := c.transformPoint(p1)
+		tp2 := c.transformPoint(p2)
+		tp3 := c.transformPoint(p3)
+		for _, p := range flattenCubic(c.current, tp1, tp2, tp3) {
+			c.appendPoint(p)
+		}
+		c.renderer.Curve(tp1.X, tp1.Y, tp2.X, tp2.Y, tp3.X, tp3.Y)
+
+		angle += step
+		remaining -= step
+	}
+}
+
+// transformPoint applies the current transform to a pathPoint.
+func (c *Context) transformPoint(p pathPoint) pathPoint {
+	x, y := c.transform.Transform(p.X, p.Y)
+	return pathPoint{x, y}
+}
+
+// ClosePath draws a straight line back to the start of the current subpath
+// and marks it as closed.
+func (c *Context) ClosePath() {
+	if !c.hasCurrent || len(c.subpaths) == 0 {
+		return
+	}
+	last := &c.subpaths[len(c.subpaths)-1]
+	last.closed = true
+	c.current = c.pathStart
+	c.renderer.Close()
+}
+
+// appendPoint adds a point to the current subpath, starting one if needed.
+func (c *Context) appendPoint(p pathPoint) {
+	if len(c.subpaths) == 0 {
+		c.subpaths = append(c.subpaths, subpath{points: []pathPoint{c.current}})
+	}
+	last := &c.subpaths[len(c.subpaths)-1]
+	last.points = append(last.points, p)
+	c.current = p
+}
+
+// ellipsePoint returns the point on the ellipse centered at cx,cy with
+// radii rx,ry at the given angle in radians.
+func ellipsePoint(cx, cy, rx, ry, angle float64) pathPoint {
+	return pathPoint{cx + rx*math.Cos(angle), cy + ry*math.Sin(angle)}
+}
+
+// ellipseTangent returns the unit-speed tangent direction of the ellipse
+// with radii rx,ry at the given angle, used to place Bézier control points.
+func ellipseTangent(rx, ry, angle float64) pathPoint {
+	return pathPoint{-rx * math.Sin(angle), ry * math.Cos(angle)}
+}
+
+// mid returns the midpoint between two points.
+func mid(a, b pathPoint) pathPoint {
+	return pathPoint{(a.X + b.X) / 2, (a.Y + b.Y) / 2}
+}
+
+// flattenQuadratic approximates a quadratic Bézier curve with line segments,
+// recursively subdividing via de Casteljau's algorithm until the control
+// point's deviation from the chord is below flattenTolerance. The returned
+// points exclude p0 and include p1.
+func flattenQuadratic(p0, c, p1 pathPoint) []pathPoint {
+	if quadraticFlatEnough(p0, c, p1) {
+		return []pathPoint{p1}
+	}
+	p01 := mid(p0, c)
+	p12 := mid(c, p1)
+	p012 := mid(p01, p12)
+
+	out := flattenQuadratic(p0, p01, p012)
+	out = append(out, flattenQuadratic(p012, p12, p1)...)
+	return out
+}
+
+func quadraticFlatEnough(p0, c, p1 pathPoint) bool {
+	return pointToSegmentDistance(c, p0, p1) <= flattenTolerance
+}
+
+// flattenCubic approximates a cubic Bézier curve with line segments,
+// recursively subdividing at t=0.5 via de Casteljau's algorithm until both
+// control points' deviation from the chord is below flattenTolerance. The
+// returned points exclude p0 and include p3.
+func flattenCubic(p0, c1, c2, p3 pathPoint) []pathPoint {
+	if cubicFlatEnough(p0, c1, c2, p3) {
+		return []pathPoint{p3}
+	}
+	p01 := mid(p0, c1)
+	p12 := mid(c1, c2)
+	p23 := mid(c2, p3)
+	p012 := mid(p01, p12)
+	p123 := mid(p12, p23)
+	p0123 := mid(p012, p123)
+
+	out := flattenCubic(p0, p01, p012, p0123)
+	out = append(out, flattenCubic(p0123, p123, p23, p3)...)
+	return out
+}
+
+func cubicFlatEnough(p0, c1, c2, p3 pathPoint) bool {
+	return pointToSegmentDistance(c1, p0, p3) <= flattenTolerance &&
+		pointToSegmentDistance(c2, p0, p3) <= flattenTolerance
+}
+
+// pointToSegmentDistance returns the perpendicular distance from p to the
+// line through a and b (or the distance to a, if a and b coincide).
+func pointToSegmentDistance(p, a, b pathPoint) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	return math.Abs((p.X-a.X)*dy-(p.Y-a.Y)*dx) / length
+}
+
+// SetLineWidth changes the width used by Stroke and FillStroke.
+func (c *Context) SetLineWidth(width float64) {
+	c.lineWidth = width
+}
+
+// SetLineCap changes the cap style used at the ends of open subpaths.
+func (c *Context) SetLineCap(cap LineCap) {
+	c.lineCap = cap
+}
+
+// SetLineJoin changes the join style used at subpath vertices.
+func (c *Context) SetLineJoin(join LineJoin) {
+	c.lineJoin = join
+}
+
+// SetMiterLimit changes the ratio of miter length to line width beyond
+// which JoinMiter falls back to a bevel join.
+func (c *Context) SetMiterLimit(limit float64) {
+	c.miterLimit = limit
+}
+
+// SetDash changes the dash pattern applied by Stroke and FillStroke. Pass a
+// nil or empty pattern to draw solid lines.
+func (c *Context) SetDash(dash Dash) {
+	c.dash = dash
+}
+
+// SetFillRule changes the winding rule used by Fill and FillStroke.
+func (c *Context) SetFillRule(rule WindingRule) {
+	c.fillRule = rule
+}
+
+// Fill fills the current path with the fill color, using the context's
+// winding rule, then discards the path. Drawing itself is delegated to the
+// context's Renderer (see NewContext and NewSVGContext).
+func (c *Context) Fill() {
+	c.renderer.Fill(c.fillRule)
+	c.resetPath()
+}
+
+// Stroke outlines the current path with the pen color at the context's line
+// width, cap, join and dash settings, then discards the path. Drawing
+// itself is delegated to the context's Renderer (see NewContext and
+// NewSVGContext).
+func (c *Context) Stroke() {
+	c.renderer.Stroke(c.strokeStyle())
+	c.resetPath()
+}
+
+// FillStroke fills then strokes the current path, then discards it.
+func (c *Context) FillStroke() {
+	c.renderer.Fill(c.fillRule)
+	c.renderer.Stroke(c.strokeStyle())
+	c.resetPath()
+}
+
+// strokeStyle bundles the stroke settings currently set on c for the
+// Renderer's Stroke method.
+func (c *Context) strokeStyle() StrokeStyle {
+	width := c.lineWidth
+	if width <= 0 {
+		width = 1
+	}
+	return StrokeStyle{Width: width, Cap: c.lineCap, Join: c.lineJoin, MiterLimit: c.miterLimit, Dash: c.dash}
+}
+
+// fillSubpaths fills the given subpaths with the fill color, if one is set.
+// All subpaths are combined into a single edge table and scanned together
+// under the context's fill rule, rather than filled one at a time, so a
+// subpath nested inside another cuts a hole out of it (EvenOdd) or is
+// subtracted from it when wound the opposite way (NonZero) - this is what
+// lets a ring or a letter like "O" be drawn as two subpaths rather than
+// one pre-cut polygon.
+func (c *Context) fillSubpaths(subpaths []subpath) {
+	if c.fillColor == nil || c.fillColor == color.Transparent || len(subpaths) == 0 {
+		return
+	}
+	contours := make([][]image.Point, len(subpaths))
+	for i, sp := range subpaths {
+		contours[i] = toImagePoints(sp.points)
+	}
+
+	bounds := contoursBounds(contours, c.rgba.Bounds())
+	if c.antialias {
+		fillScanline(contours, bounds, c.fillRule, true, func(x, y int, coverage float64) {
+			c.blendPixel(x, y, c.fillColor, coverage)
+		})
+	} else {
+		fillScanline(contours, bounds, c.fillRule, false, func(x, y int, coverage float64) {
+			c.fillPixelRaw(x, y)
+		})
+	}
+}
+
+// strokeSubpaths outlines the given subpaths with the pen color at the
+// context's line width, cap, join and dash settings, applying the dash
+// pattern first, if one is set.
+func (c *Context) strokeSubpaths(subpaths []subpath) {
+	if c.penColor == nil || c.penColor == color.Transparent {
+		return
+	}
+	width := c.lineWidth
+	if width <= 0 {
+		width = 1
+	}
+	for _, sp := range subpaths {
+		for _, dashed := range applyDash(sp, c.dash) {
+			outline := strokeOutline(dashed.points, dashed.closed, width, c.lineCap, c.lineJoin, c.miterLimit)
+			if outline != nil {
+				c.fillWithColor(toImagePoints(outline), c.penColor)
+			}
+		}
+	}
+}
+
+// fillWithColor fills the given polygon with clr, bypassing the context's
+// own pen/fill colors and its transform, since the points are already in
+// device space. It is used to rasterize the polygon produced by stroking a
+// path, reusing the same fill logic as Polygon.
+func (c *Context) fillWithColor(points []image.Point, clr color.Color) {
+	prevFill, prevPen, prevTransform := c.fillColor, c.penColor, c.transform
+	c.fillColor = clr
+	c.penColor = color.Transparent
+	c.transform = identityMatrix()
+	c.Polygon(points)
+	c.fillColor, c.penColor, c.transform = prevFill, prevPen, prevTransform
+}
+
+// strokeOutline computes the polygon outline produced by stroking the given
+// flattened points at the given width, cap and join settings. For open
+// paths the outline walks the offset points on one side, caps the end,
+// walks back along the other side, then caps the start. For closed paths
+// it produces two nested loops - an outer one walked forward and an inner
+// one walked backward - which render as a ring under either winding rule.
+func strokeOutline(points []pathPoint, closed bool, width float64, cap LineCap, join LineJoin, miterLimit float64) []pathPoint {
+	points = dedupePoints(points)
+	n := len(points)
+	if n < 2 || width <= 0 {
+		return nil
+	}
+	half := width / 2
+
+	type edge struct {
+		a, b, dir, normal pathPoint
+	}
+	count := n - 1
+	if closed {
+		count = n
+	}
+	edges := make([]edge, count)
+	for i := range edges {
+		a := points[i]
+		b := points[(i+1)%n]
+		dir, normal := edgeDirAndNormal(a, b)
+		edges[i] = edge{a, b, dir, normal}
+	}
+
+	var left, right []pathPoint
+	for i, e := range edges {
+		left = append(left,
+			pathPoint{e.a.X + e.normal.X*half, e.a.Y + e.normal.Y*half},
+			pathPoint{e.b.X + e.normal.X*half, e.b.Y + e.normal.Y*half})
+		right = append(right,
+			pathPoint{e.a.X - e.normal.X*half, e.a.Y - e.normal.Y*half},
+			pathPoint{e.b.X - e.normal.X*half, e.b.Y - e.normal.Y*half})
+
+		var next edge
+		hasNext := false
+		if i+1 < len(edges) {
+			next, hasNext = edges[i+1], true
+		} else if closed {
+			next, hasNext = edges[0], true
+		}
+		if hasNext {
+			ang1 := math.Atan2(e.normal.Y, e.normal.X)
+			ang2 := math.Atan2(next.normal.Y, next.normal.X)
+			left = append(left, joinGeometry(e.b, half, ang1, ang2, join, miterLimit)...)
+			right = append(right, joinGeometry(e.b, -half, ang1, ang2, join, miterLimit)...)
+		}
+	}
+
+	if closed {
+		outline := append([]pathPoint{}, left...)
+		outline = append(outline, reversePoints(right)...)
+		return outline
+	}
+
+	first, last := edges[0], edges[len(edges)-1]
+	outline := append([]pathPoint{}, left...)
+	outline = append(outline, capGeometry(last.b, half, last.dir, cap)...)
+	outline = append(outline, reversePoints(right)...)
+	outline = append(outline, capGeometry(first.a, half, pathPoint{-first.dir.X, -first.dir.Y}, cap)...)
+	return outline
+}
+
+// joinGeometry returns the points to insert between two offset edges that
+// meet at a vertex, beyond the edges' own offset endpoints. sideHalf is the
+// signed half-width of the chain being built (positive for the left offset,
+// negative for the right), and ang1/ang2 are the angles of the two edges'
+// (left) normals.
+func joinGeometry(v pathPoint, sideHalf, ang1, ang2 float64, join LineJoin, miterLimit float64) []pathPoint {
+	switch join {
+	case JoinRound:
+		if sideHalf < 0 {
+			// The right offset lies opposite the left normals.
+			return arcPoints(v, -sideHalf, ang1+math.Pi, ang2+math.Pi)
+		}
+		return arcPoints(v, sideHalf, ang1, ang2)
+	case JoinMiter:
+		if mp, ok := miterPoint(v, ang1, ang2, sideHalf, miterLimit); ok {
+			return []pathPoint{mp}
+		}
+	}
+	return nil
+}
+
+// miterPoint returns the intersection of the two offset edges extended from
+// their shared vertex, or false if the miter would exceed miterLimit times
+// the half-width (in which case the caller should fall back to a bevel).
+func miterPoint(v pathPoint, ang1, ang2, sideHalf, miterLimit float64) (pathPoint, bool) {
+	delta := normalizeAngle(ang2 - ang1)
+	if math.Abs(delta) < 1e-6 {
+		return pathPoint{}, false
+	}
+	half := delta / 2
+	cosHalf := math.Cos(half)
+	if math.Abs(cosHalf) < 1e-6 {
+		return pathPoint{}, false
+	}
+	miterLen := sideHalf / cosHalf
+	if math.Abs(miterLen) > math.Abs(sideHalf)*miterLimit {
+		return pathPoint{}, false
+	}
+	bisector := ang1 + half
+	return pathPoint{v.X + miterLen*math.Cos(bisector), v.Y + miterLen*math.Sin(bisector)}, true
+}
+
+// capGeometry returns the points to insert at an open subpath's end, beyond
+// the offset endpoints already in the outline. outwardDir points away from
+// the subpath, in the direction the cap should bulge.
+func capGeometry(v pathPoint, half float64, outwardDir pathPoint, cap LineCap) []pathPoint {
+	switch cap {
+	case CapRound:
+		return capPoints(v, half, outwardDir)
+	case CapSquare:
+		ln := pathPoint{-outwardDir.Y, outwardDir.X}
+		rn := pathPoint{outwardDir.Y, -outwardDir.X}
+		ext := pathPoint{outwardDir.X * half, outwardDir.Y * half}
+		return []pathPoint{
+			{v.X + ln.X*half + ext.X, v.Y + ln.Y*half + ext.Y},
+			{v.X + rn.X*half + ext.X, v.Y + rn.Y*half + ext.Y},
+		}
+	default: // CapButt
+		return nil
+	}
+}
+
+// capPoints returns the interior points of a semicircle of the given
+// radius around v, bulging towards outwardDir.
+func capPoints(v pathPoint, radius float64, outwardDir pathPoint) []pathPoint {
+	steps := arcSteps(radius)
+	startAngle := math.Atan2(outwardDir.Y, outwardDir.X) + math.Pi/2
+	out := make([]pathPoint, 0, steps-1)
+	for k := 1; k < steps; k++ {
+		angle := startAngle - math.Pi*float64(k)/float64(steps)
+		out = append(out, pathPoint{v.X + radius*math.Cos(angle), v.Y + radius*math.Sin(angle)})
+	}
+	return out
+}
+
+// arcPoints returns the interior points of the arc of the given radius
+// around center, sweeping from fromAngle to toAngle the short way around.
+func arcPoints(center pathPoint, radius, fromAngle, toAngle float64) []pathPoint {
+	delta := normalizeAngle(toAngle - fromAngle)
+	steps := arcSteps(radius)
+	out := make([]pathPoint, 0, steps-1)
+	for k := 1; k < steps; k++ {
+		angle := fromAngle + delta*float64(k)/float64(steps)
+		out = append(out, pathPoint{center.X + radius*math.Cos(angle), center.Y + radius*math.Sin(angle)})
+	}
+	return out
+}
+
+// arcSteps picks a segment count for approximating a half-turn arc of the
+// given radius with straight lines, coarser for small radii.
+func arcSteps(radius float64) int {
+	steps := int(math.Ceil(math.Pi * radius / 4))
+	if steps < 4 {
+		steps = 4
+	}
+	return steps
+}
+
+// normalizeAngle wraps an angle in radians to (-π, π].
+func normalizeAngle(a float64) float64 {
+	for a > math.Pi {
+		a -= 2 * math.Pi
+	}
+	for a <= -math.Pi {
+		a += 2 * math.Pi
+	}
+	return a
+}
+
+// edgeDirAndNormal returns the unit direction from a to b and its left
+// normal (zero vectors if a and b coincide).
+func edgeDirAndNormal(a, b pathPoint) (dir, normal pathPoint) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return pathPoint{}, pathPoint{}
+	}
+	dir = pathPoint{dx / length, dy / length}
+	normal = pathPoint{-dir.Y, dir.X}
+	return
+}
+
+// dedupePoints removes consecutive duplicate points.
+func dedupePoints(points []pathPoint) []pathPoint {
+	if len(points) == 0 {
+		return points
+	}
+	out := []pathPoint{points[0]}
+	for _, p := range points[1:] {
+		last := out[len(out)-1]
+		if p.X != last.X || p.Y != last.Y {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// reversePoints returns a new slice with points in reverse order.
+func reversePoints(points []pathPoint) []pathPoint {
+	out := make([]pathPoint, len(points))
+	for i, p := range points {
+		out[len(out)-1-i] = p
+	}
+	return out
+}
+
+// toImagePoints rounds a slice of floating point path points to the nearest
+// integer pixel coordinates.
+func toImagePoints(points []pathPoint) []image.Point {
+	out := make([]image.Point, len(points))
+	for i, p := range points {
+		out[i] = image.Point{X: int(math.Round(p.X)), Y: int(math.Round(p.Y))}
+	}
+	return out
+}
+
+// applyDash splits a subpath into the "on" pieces of the given dash
+// pattern. An empty pattern returns the subpath unchanged.
+func applyDash(sp subpath, dash Dash) []subpath {
+	if len(dash.Pattern) == 0 {
+		return []subpath{sp}
+	}
+
+	points := sp.points
+	if sp.closed && len(points) > 0 {
+		points = append(append([]pathPoint{}, points...), points[0])
+	}
+	if len(points) < 2 {
+		return []subpath{sp}
+	}
+
+	var out []subpath
+	var current []pathPoint
+
+	patternLen := 0.0
+	for _, d := range dash.Pattern {
+		patternLen += d
+	}
+	if patternLen <= 0 {
+		return []subpath{sp}
+	}
+
+	// Walk the pattern forward by dash.Phase to find the starting index
+	// and the remaining length of that entry.
+	phase := math.Mod(dash.Phase, patternLen)
+	if phase < 0 {
+		phase += patternLen
+	}
+	idx := 0
+	for phase >= dash.Pattern[idx] {
+		phase -= dash.Pattern[idx]
+		idx = (idx + 1) % len(dash.Pattern)
+	}
+	on := idx%2 == 0
+	remaining := dash.Pattern[idx] - phase
+
+	if on {
+		current = append(current, points[0])
+	}
+
+	for i := 0; i < len(points)-1; i++ {
+		a, b := points[i], points[i+1]
+		segLen := math.Hypot(b.X-a.X, b.Y-a.Y)
+		travelled := 0.0
+		for travelled < segLen {
+			step := segLen - travelled
+			if step > remaining {
+				step = remaining
+			}
+			travelled += step
+			remaining -= step
+
+			t := travelled / segLen
+			p := pathPoint{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+
+			if on {
+				current = append(current, p)
+			}
+
+			if remaining <= 0 {
+				if on && len(current) > 1 {
+					out = append(out, subpath{points: current})
+				}
+				current = nil
+				idx = (idx + 1) % len(dash.Pattern)
+				remaining = dash.Pattern[idx]
+				on = !on
+				if on {
+					current = append(current, p)
+				}
+			}
+		}
+	}
+
+	if on && len(current) > 1 {
+		out = append(out, subpath{points: current})
+	}
+
+	return out
+}
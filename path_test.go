@@ -0,0 +1,107 @@
+package draw
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// TestFillEvenOddHole verifies that a path made of two nested rectangles
+// leaves the inner rectangle unfilled under the EvenOdd winding rule,
+// rather than the second subpath simply overwriting the first with the
+// same fill color (see fillSubpaths).
+func TestFillEvenOddHole(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	c := NewContext(img)
+	c.SetPen(color.Transparent)
+	c.SetFill(color.White)
+	c.SetFillRule(EvenOdd)
+
+	c.MoveTo(2, 2)
+	c.LineTo(18, 2)
+	c.LineTo(18, 18)
+	c.LineTo(2, 18)
+	c.ClosePath()
+
+	c.MoveTo(6, 6)
+	c.LineTo(14, 6)
+	c.LineTo(14, 14)
+	c.LineTo(6, 14)
+	c.ClosePath()
+
+	c.Fill()
+
+	if _, _, _, a := img.At(10, 10).RGBA(); a != 0 {
+		t.Errorf("inner rectangle at (10,10) should be an unfilled hole under EvenOdd, got alpha %d", a)
+	}
+	if _, _, _, a := img.At(4, 4).RGBA(); a == 0 {
+		t.Errorf("ring between the two rectangles at (4,4) should be filled, got alpha 0")
+	}
+}
+
+// TestStrokeCapSquareExtendsPastEndpoint verifies that CapSquare extends a
+// stroked line's outline by half its width beyond the line's endpoints,
+// while CapButt stops exactly at them (see capGeometry).
+func TestStrokeCapSquareExtendsPastEndpoint(t *testing.T) {
+	stroke := func(cap LineCap) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 30, 10))
+		c := NewContext(img)
+		c.SetPen(color.White)
+		c.SetFill(color.Transparent)
+		c.SetLineWidth(4)
+		c.SetLineCap(cap)
+		c.MoveTo(10, 5)
+		c.LineTo(20, 5)
+		c.Stroke()
+		return img
+	}
+
+	butt := stroke(CapButt)
+	if _, _, _, a := butt.At(8, 5).RGBA(); a != 0 {
+		t.Errorf("CapButt: pixel at (8,5), 2px before the line's start, should be unfilled, got alpha %d", a)
+	}
+
+	square := stroke(CapSquare)
+	if _, _, _, a := square.At(8, 5).RGBA(); a == 0 {
+		t.Errorf("CapSquare: pixel at (8,5), 2px before the line's start, should be filled by the extended cap, got alpha 0")
+	}
+}
+
+// TestMiterPointFallsBackBelowLimit verifies that miterPoint returns a point
+// for a shallow join within miterLimit, but reports false for a sharp join
+// whose miter length would exceed it, so the caller can fall back to a
+// bevel (see joinGeometry).
+func TestMiterPointFallsBackBelowLimit(t *testing.T) {
+	v := pathPoint{0, 0}
+	const sideHalf = 1.0
+	const miterLimit = 4.0
+
+	if _, ok := miterPoint(v, 0, 0.3, sideHalf, miterLimit); !ok {
+		t.Errorf("shallow join (0.3 rad) should produce a miter point within the limit")
+	}
+	if _, ok := miterPoint(v, 0, 3.0, sideHalf, miterLimit); ok {
+		t.Errorf("sharp join (3.0 rad) should exceed the miter limit and report false")
+	}
+}
+
+// TestApplyDashPhaseWraps verifies that a dash Phase that is a multiple of
+// the pattern's total length, or negative, wraps to the same starting point
+// in the pattern as the equivalent phase in [0, patternLen) - see the
+// "walk the pattern forward by dash.Phase" comment in applyDash.
+func TestApplyDashPhaseWraps(t *testing.T) {
+	sp := subpath{points: []pathPoint{{0, 0}, {10, 0}}}
+	pattern := []float64{4, 2}
+
+	base := applyDash(sp, Dash{Pattern: pattern, Phase: 0})
+	wrapped := applyDash(sp, Dash{Pattern: pattern, Phase: 6}) // one full pattern length
+	if !reflect.DeepEqual(base, wrapped) {
+		t.Errorf("Phase: 6 (one full pattern length) should dash identically to Phase: 0\nbase:    %+v\nwrapped: %+v", base, wrapped)
+	}
+
+	negative := applyDash(sp, Dash{Pattern: pattern, Phase: -2})
+	equivalent := applyDash(sp, Dash{Pattern: pattern, Phase: 4}) // -2 + patternLen
+	if !reflect.DeepEqual(negative, equivalent) {
+		t.Errorf("Phase: -2 should dash identically to Phase: 4\nnegative:   %+v\nequivalent: %+v", negative, equivalent)
+	}
+}
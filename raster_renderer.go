@@ -0,0 +1,197 @@
+package draw
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// rasterRenderer is the Renderer behind NewContext. It rasterizes straight
+// into an image.RGBA, reusing the same scanline fill and stroke-outline
+// logic as Polygon - so it tracks no path state of its own: Fill and
+// Stroke simply rasterize the subpaths already accumulated on ctx by
+// MoveTo/LineTo/QuadraticTo/CubicTo/ArcTo. It does track a style stack for
+// Save/Restore, mirroring svgRenderer, since ctx.penColor, ctx.fillColor
+// and ctx.fontDrawer.Face are otherwise not affected by Push/Pop at all.
+type rasterRenderer struct {
+	ctx   *Context
+	saved []rasterRendererState
+}
+
+// rasterRendererState is the style snapshot pushed and popped by Save/Restore.
+type rasterRendererState struct {
+	penColor, fillColor color.Color
+	fontFace            font.Face
+}
+
+func (r *rasterRenderer) MoveTo(x, y float64)                    {}
+func (r *rasterRenderer) LineTo(x, y float64)                    {}
+func (r *rasterRenderer) Curve(cx1, cy1, cx2, cy2, x, y float64) {}
+func (r *rasterRenderer) Close()                                 {}
+
+// Save snapshots the context's pen color, fill color and font face so a
+// later Restore can undo any SetPen/SetFill/SetFontFace calls made since.
+func (r *rasterRenderer) Save() {
+	r.saved = append(r.saved, rasterRendererState{
+		penColor:  r.ctx.penColor,
+		fillColor: r.ctx.fillColor,
+		fontFace:  r.ctx.fontDrawer.Face,
+	})
+}
+
+// Restore reverts the context's pen color, fill color and font face to
+// their state at the matching Save. It is a no-op if there is nothing left
+// to restore.
+func (r *rasterRenderer) Restore() {
+	n := len(r.saved)
+	if n == 0 {
+		return
+	}
+	state := r.saved[n-1]
+	r.saved = r.saved[:n-1]
+	r.ctx.penColor = state.penColor
+	r.ctx.fillColor = state.fillColor
+	r.ctx.fontDrawer.Face = state.fontFace
+}
+
+// Fill rasterizes ctx's accumulated subpaths with ctx's fill color, using
+// the same active-edge-table scanline sweep as Polygon.
+func (r *rasterRenderer) Fill(rule WindingRule) {
+	r.ctx.fillSubpaths(r.ctx.subpaths)
+}
+
+// Stroke rasterizes the outline of ctx's accumulated subpaths with ctx's
+// pen color, using the same stroke-to-fill geometry as strokeSubpaths.
+func (r *rasterRenderer) Stroke(style StrokeStyle) {
+	r.ctx.strokeSubpaths(r.ctx.subpaths)
+}
+
+// Text draws text with its baseline origin at x,y, after applying ctx's
+// current transform. When the transform is a pure translation, it is drawn
+// directly with the font library's own rasterizer. Otherwise - under
+// Scale, Rotate or Shear - the glyphs themselves must follow the transform
+// too, so the text is first drawn upright into an offscreen mask at its
+// natural size, then warped into ctx.rgba by inverse-mapping each affected
+// device pixel back into the mask and bilinear-sampling its alpha.
+func (r *rasterRenderer) Text(x, y float64, text string, face font.Face, clr color.Color) {
+	if isTranslationOnly(r.ctx.transform) {
+		tx, ty := r.ctx.transform.Transform(x, y)
+		r.ctx.fontDrawer.Face = face
+		r.ctx.fontDrawer.Src = image.NewUniform(clr)
+		r.ctx.fontDrawer.Dot = fixed.Point26_6{
+			X: fixed.Int26_6(tx * 64),
+			Y: fixed.Int26_6(ty * 64),
+		}
+		r.ctx.fontDrawer.DrawString(text)
+		return
+	}
+	r.drawTransformedText(x, y, text, face, clr)
+}
+
+// isTranslationOnly reports whether m's linear part is the identity, i.e.
+// it only moves the origin without scaling, rotating or shearing.
+func isTranslationOnly(m Matrix) bool {
+	return m.a == 1 && m.b == 0 && m.c == 0 && m.d == 1
+}
+
+// drawTransformedText renders text upright into an offscreen mask sized to
+// fit it at the face's natural scale, with originX,originY marking the
+// mask pixel that corresponds to the untransformed x,y. It then walks the
+// device-space bounding box of the transformed mask, and for each pixel
+// inverse-maps back to a mask coordinate and bilinear-samples its alpha,
+// blending clr into ctx.rgba with that coverage via blendPixel.
+func (r *rasterRenderer) drawTransformedText(x, y float64, text string, face font.Face, clr color.Color) {
+	ctx := r.ctx
+	metrics := face.Metrics()
+	ascent := fixedToFloat(metrics.Ascent)
+	descent := fixedToFloat(metrics.Descent)
+	width := fixedToFloat(font.MeasureString(face, text))
+
+	// Pad the mask by a pixel on each side so bilinear sampling near its
+	// edges has real (fully transparent) neighbours to blend with, rather
+	// than clamping into a hard cutoff.
+	const pad = 1.0
+	maskW := int(math.Ceil(width)) + 2*pad + 1
+	maskH := int(math.Ceil(ascent+descent)) + 2*pad + 1
+	originX := pad
+	originY := pad + ascent
+
+	mask := image.NewRGBA(image.Rect(0, 0, maskW, maskH))
+	drawer := &font.Drawer{
+		Dst:  mask,
+		Src:  image.NewUniform(clr),
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: fixed.Int26_6(originX * 64),
+			Y: fixed.Int26_6(originY * 64),
+		},
+	}
+	drawer.DrawString(text)
+
+	// The four corners of the mask, expressed relative to the untransformed
+	// x,y, bound the area of device space the transformed glyphs can touch.
+	corners := [4][2]float64{
+		{0 - originX, 0 - originY},
+		{float64(maskW) - originX, 0 - originY},
+		{0 - originX, float64(maskH) - originY},
+		{float64(maskW) - originX, float64(maskH) - originY},
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range corners {
+		dx, dy := ctx.transform.Transform(x+corner[0], y+corner[1])
+		minX, maxX = math.Min(minX, dx), math.Max(maxX, dx)
+		minY, maxY = math.Min(minY, dy), math.Max(maxY, dy)
+	}
+
+	imgBounds := ctx.rgba.Bounds()
+	startX := maxInt(int(math.Floor(minX)), imgBounds.Min.X)
+	endX := minInt(int(math.Ceil(maxX))+1, imgBounds.Max.X)
+	startY := maxInt(int(math.Floor(minY)), imgBounds.Min.Y)
+	endY := minInt(int(math.Ceil(maxY))+1, imgBounds.Max.Y)
+
+	inverse := ctx.transform.Inverse()
+	for dy := startY; dy < endY; dy++ {
+		for dx := startX; dx < endX; dx++ {
+			ux, uy := inverse.Transform(float64(dx)+0.5, float64(dy)+0.5)
+			coverage := sampleMaskAlpha(mask, ux-x+originX, uy-y+originY)
+			if coverage > 0 {
+				ctx.blendPixel(dx, dy, clr, coverage)
+			}
+		}
+	}
+}
+
+// sampleMaskAlpha bilinearly samples the alpha channel of mask at the
+// fractional coordinate lx,ly, returning a coverage in [0, 1]. Coordinates
+// outside mask's bounds sample as fully transparent.
+func sampleMaskAlpha(mask *image.RGBA, lx, ly float64) float64 {
+	bounds := mask.Bounds()
+	x0 := int(math.Floor(lx))
+	y0 := int(math.Floor(ly))
+	fx := lx - float64(x0)
+	fy := ly - float64(y0)
+
+	at := func(px, py int) float64 {
+		if px < bounds.Min.X || px >= bounds.Max.X || py < bounds.Min.Y || py >= bounds.Max.Y {
+			return 0
+		}
+		_, _, _, a := mask.At(px, py).RGBA()
+		return float64(a) / 0xffff
+	}
+
+	return at(x0, y0)*(1-fx)*(1-fy) +
+		at(x0+1, y0)*fx*(1-fy) +
+		at(x0, y0+1)*(1-fx)*fy +
+		at(x0+1, y0+1)*fx*fy
+}
+
+// SetPen, SetFill and SetFontFace are no-ops: the raster backend reads
+// ctx.penColor, ctx.fillColor and ctx.fontDrawer.Face directly, and
+// Context's own SetPen/SetFill/SetFontFace already keep those up to date.
+func (r *rasterRenderer) SetPen(clr color.Color)     {}
+func (r *rasterRenderer) SetFill(clr color.Color)    {}
+func (r *rasterRenderer) SetFontFace(face font.Face) {}
@@ -0,0 +1,65 @@
+package draw
+
+import (
+	"image/color"
+
+	"golang.org/x/image/font"
+)
+
+// StrokeStyle bundles the stroke settings a Renderer needs for Stroke,
+// mirroring the fields SetLineWidth, SetLineCap, SetLineJoin,
+// SetMiterLimit and SetDash store on Context.
+type StrokeStyle struct {
+	Width      float64
+	Cap        LineCap
+	Join       LineJoin
+	MiterLimit float64
+	Dash       Dash
+}
+
+// Renderer is the pluggable backend behind the unified path subsystem
+// (MoveTo, LineTo, QuadraticTo, CubicTo, ArcTo, ClosePath, Fill, Stroke,
+// FillStroke) and Text. NewContext targets the default backend, which
+// rasterizes straight into an image.RGBA; NewSVGContext targets a backend
+// that instead accumulates resolution-independent SVG markup. Context's
+// drawing methods are identical either way - only the backend differs.
+//
+// QuadraticTo and ArcTo never reach a Renderer directly: Context expresses
+// both as one or more cubic Curve calls before forwarding them, so a
+// Renderer only needs to implement the primitives below. Push and Pop
+// forward to Save and Restore.
+type Renderer interface {
+	MoveTo(x, y float64)
+	LineTo(x, y float64)
+	Curve(cx1, cy1, cx2, cy2, x, y float64)
+	Close()
+
+	Fill(rule WindingRule)
+	Stroke(style StrokeStyle)
+	Text(x, y float64, text string, face font.Face, clr color.Color)
+
+	SetPen(clr color.Color)
+	SetFill(clr color.Color)
+	SetFontFace(face font.Face)
+
+	Save()
+	Restore()
+}
+
+// finalizer is implemented by Renderers that need an explicit flush once
+// drawing is done, such as svgRenderer writing its closing tag. The
+// image.RGBA backend needs no such step, since it is rendered directly
+// into rgba as each primitive is drawn.
+type finalizer interface {
+	Finalize() error
+}
+
+// Finalize flushes any output buffered by the context's renderer. It is a
+// no-op for the image.RGBA backend created by NewContext; for the SVG
+// backend created by NewSVGContext it writes the closing </svg> tag.
+func (c *Context) Finalize() error {
+	if f, ok := c.renderer.(finalizer); ok {
+		return f.Finalize()
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package draw
+
+import (
+	"image"
+	"sort"
+)
+
+// scanlineEdge is one non-horizontal edge of a polygon, prepared for the
+// active-edge-table scanline fill used by Polygon.
+type scanlineEdge struct {
+	yMin, yMax int
+	x          float64
+	slope      float64
+	winding    int
+}
+
+// buildScanlineEdges converts a list of contours into the non-horizontal
+// edges used to scan them together, one per side of each contour including
+// its own closing edge back to its first point - a contour never closes
+// into another one. Horizontal edges never contribute a crossing and are
+// dropped.
+func buildScanlineEdges(contours [][]image.Point) []scanlineEdge {
+	var edges []scanlineEdge
+	for _, points := range contours {
+		n := len(points)
+		for i := 0; i < n; i++ {
+			p0 := points[i]
+			p1 := points[(i+1)%n]
+			if p0.Y == p1.Y {
+				continue
+			}
+
+			winding := 1
+			if p0.Y > p1.Y {
+				p0, p1 = p1, p0
+				winding = -1
+			}
+
+			edges = append(edges, scanlineEdge{
+				yMin:    p0.Y,
+				yMax:    p1.Y,
+				x:       float64(p0.X),
+				slope:   float64(p1.X-p0.X) / float64(p1.Y-p0.Y),
+				winding: winding,
+			})
+		}
+	}
+	return edges
+}
+
+// fillScanline fills the contours described by contours within bounds using
+// an active-edge-table scanline sweep, calling plot for every pixel touched
+// according to rule. Passing more than one contour combines them into a
+// single edge table under the shared winding rule, so a second contour
+// nested inside a first cuts a hole out of it (EvenOdd) or is subtracted
+// from it when its points wind the opposite way (NonZero), rather than
+// being filled as an independent shape. For each scanline, edges starting
+// there are added to the active list, edges ending there are removed, and
+// the list is sorted by current x before filling the spans between
+// crossings; each active edge's x is then advanced by its slope. This runs
+// in time linear in the number of edge crossings per scanline.
+//
+// When aa is false, plot is called once per fully covered pixel with a
+// coverage of 1. When aa is true, each scanline's spans are instead
+// accumulated into a per-pixel horizontal coverage buffer - the fraction of
+// the pixel's [x, x+1) column covered by the spans on that row - and plot
+// is called once per pixel with a non-zero coverage, in x order.
+func fillScanline(contours [][]image.Point, bounds image.Rectangle, rule WindingRule, aa bool, plot func(x, y int, coverage float64)) {
+	edges := buildScanlineEdges(contours)
+	if len(edges) == 0 {
+		return
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].yMin < edges[j].yMin })
+
+	var active []*scanlineEdge
+	next := 0
+
+	width := bounds.Dx()
+	var coverage []float64
+	if aa && width > 0 {
+		coverage = make([]float64, width)
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for next < len(edges) && edges[next].yMin <= y {
+			e := &edges[next]
+			// An edge starting above bounds joins at the first scanline,
+			// with x advanced over the rows it skipped.
+			if e.yMin < y {
+				e.x += e.slope * float64(y-e.yMin)
+			}
+			active = append(active, e)
+			next++
+		}
+
+		kept := active[:0]
+		for _, e := range active {
+			if e.yMax > y {
+				kept = append(kept, e)
+			}
+		}
+		active = kept
+		if len(active) == 0 {
+			continue
+		}
+
+		sort.Slice(active, func(i, j int) bool { return active[i].x < active[j].x })
+
+		if aa {
+			for i := range coverage {
+				coverage[i] = 0
+			}
+			accumulate := func(x0, x1 float64) { accumulateCoverage(coverage, bounds, x0, x1) }
+			walkSpans(active, rule, accumulate)
+			for i, cov := range coverage {
+				if cov > 0 {
+					plot(bounds.Min.X+i, y, cov)
+				}
+			}
+		} else {
+			fill := func(x0, x1 float64) { fillSpan(x0, x1, y, bounds, plot) }
+			walkSpans(active, rule, fill)
+		}
+
+		for _, e := range active {
+			e.x += e.slope
+		}
+	}
+}
+
+// walkSpans calls emit once for each [x0, x1) span that lies inside the
+// polygon on the current scanline, given its active edges sorted by x.
+func walkSpans(active []*scanlineEdge, rule WindingRule, emit func(x0, x1 float64)) {
+	switch rule {
+	case NonZero:
+		winding := 0
+		for i := 0; i+1 < len(active); i++ {
+			winding += active[i].winding
+			if winding != 0 {
+				emit(active[i].x, active[i+1].x)
+			}
+		}
+	default: // EvenOdd
+		for i := 0; i+1 < len(active); i += 2 {
+			emit(active[i].x, active[i+1].x)
+		}
+	}
+}
+
+// fillSpan calls plot with full coverage for the pixels on scanline y whose
+// centers fall in [x0, x1), clipped to bounds.
+func fillSpan(x0, x1 float64, y int, bounds image.Rectangle, plot func(x, y int, coverage float64)) {
+	from := ceilHalfUp(x0 - 0.5)
+	to := ceilHalfUp(x1 - 0.5)
+	if from < bounds.Min.X {
+		from = bounds.Min.X
+	}
+	if to > bounds.Max.X {
+		to = bounds.Max.X
+	}
+	for x := from; x < to; x++ {
+		plot(x, y, 1)
+	}
+}
+
+// accumulateCoverage adds the fraction of each pixel column in bounds
+// covered by [x0, x1) to the matching entry in coverage.
+func accumulateCoverage(coverage []float64, bounds image.Rectangle, x0, x1 float64) {
+	if x0 < float64(bounds.Min.X) {
+		x0 = float64(bounds.Min.X)
+	}
+	if x1 > float64(bounds.Max.X) {
+		x1 = float64(bounds.Max.X)
+	}
+	if x0 >= x1 {
+		return
+	}
+
+	first := floorInt(x0)
+	last := floorInt(x1)
+	for px := first; px <= last; px++ {
+		if px < bounds.Min.X || px >= bounds.Max.X {
+			continue
+		}
+		left := maxFloat(x0, float64(px))
+		right := minFloat(x1, float64(px+1))
+		if right > left {
+			coverage[px-bounds.Min.X] += right - left
+		}
+	}
+}
+
+// contoursBounds returns the bounding box of every point across contours,
+// clipped to img.
+func contoursBounds(contours [][]image.Point, img image.Rectangle) image.Rectangle {
+	minX, maxX := img.Max.X, img.Min.X
+	minY, maxY := img.Max.Y, img.Min.Y
+	for _, points := range contours {
+		for _, p := range points {
+			minX = minInt(p.X, minX)
+			maxX = maxInt(p.X, maxX)
+			minY = minInt(p.Y, minY)
+			maxY = maxInt(p.Y, maxY)
+		}
+	}
+	minX = maxInt(minX, img.Min.X)
+	minY = maxInt(minY, img.Min.Y)
+	maxX = minInt(maxX, img.Max.X)
+	maxY = minInt(maxY, img.Max.Y)
+	return image.Rect(minX, minY, maxX, maxY)
+}
+
+func floorInt(x float64) int {
+	i := int(x)
+	if x < float64(i) {
+		i--
+	}
+	return i
+}
+
+func ceilHalfUp(x float64) int {
+	i := floorInt(x)
+	if float64(i) < x {
+		i++
+	}
+	return i
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,449 @@
+package draw
+
+import (
+	"math"
+	"strconv"
+)
+
+// DrawSVGPath parses an SVG path data string (the contents of a path
+// element's "d" attribute) and renders it through the path subsystem,
+// stroking with the current pen and filling with the current fill color.
+// It supports the full SVG path command vocabulary - M/m, L/l, H/h, V/v,
+// C/c, S/s, Q/q, T/t, A/a and Z/z - in both absolute and relative form,
+// including the implicit repetition of a command when it is followed by
+// further coordinate pairs, and the smooth-curve reflection used by S/s
+// and T/t.
+func (c *Context) DrawSVGPath(d string) {
+	p := &svgScanner{s: d}
+
+	var curX, curY float64
+	var startX, startY float64
+	var lastCmd byte
+	var lastControlX, lastControlY float64
+	haveLastControl := false
+
+	for {
+		cmdByte, ok := p.peekCommand()
+		if !ok {
+			break
+		}
+		p.next()
+		relative := cmdByte >= 'a' && cmdByte <= 'z'
+		cmd := upperSVGCommand(cmdByte)
+		first := true
+
+	argsLoop:
+		for {
+			switch cmd {
+			case 'M':
+				x, ok1 := p.scanNumber()
+				y, ok2 := p.scanNumber()
+				if !ok1 || !ok2 {
+					break argsLoop
+				}
+				if relative {
+					x += curX
+					y += curY
+				}
+				if first {
+					c.MoveTo(x, y)
+					startX, startY = x, y
+				} else {
+					c.LineTo(x, y)
+				}
+				curX, curY = x, y
+				haveLastControl = false
+
+			case 'L':
+				x, ok1 := p.scanNumber()
+				y, ok2 := p.scanNumber()
+				if !ok1 || !ok2 {
+					break argsLoop
+				}
+				if relative {
+					x += curX
+					y += curY
+				}
+				c.LineTo(x, y)
+				curX, curY = x, y
+				haveLastControl = false
+
+			case 'H':
+				x, ok := p.scanNumber()
+				if !ok {
+					break argsLoop
+				}
+				if relative {
+					x += curX
+				}
+				c.LineTo(x, curY)
+				curX = x
+				haveLastControl = false
+
+			case 'V':
+				y, ok := p.scanNumber()
+				if !ok {
+					break argsLoop
+				}
+				if relative {
+					y += curY
+				}
+				c.LineTo(curX, y)
+				curY = y
+				haveLastControl = false
+
+			case 'C':
+				x1, ok1 := p.scanNumber()
+				y1, ok2 := p.scanNumber()
+				x2, ok3 := p.scanNumber()
+				y2, ok4 := p.scanNumber()
+				x, ok5 := p.scanNumber()
+				y, ok6 := p.scanNumber()
+				if !(ok1 && ok2 && ok3 && ok4 && ok5 && ok6) {
+					break argsLoop
+				}
+				if relative {
+					x1, y1, x2, y2, x, y = x1+curX, y1+curY, x2+curX, y2+curY, x+curX, y+curY
+				}
+				c.CubicTo(x1, y1, x2, y2, x, y)
+				lastControlX, lastControlY, haveLastControl = x2, y2, true
+				curX, curY = x, y
+
+			case 'S':
+				x2, ok1 := p.scanNumber()
+				y2, ok2 := p.scanNumber()
+				x, ok3 := p.scanNumber()
+				y, ok4 := p.scanNumber()
+				if !(ok1 && ok2 && ok3 && ok4) {
+					break argsLoop
+				}
+				if relative {
+					x2, y2, x, y = x2+curX, y2+curY, x+curX, y+curY
+				}
+				x1, y1 := curX, curY
+				if haveLastControl && (lastCmd == 'C' || lastCmd == 'S') {
+					x1, y1 = 2*curX-lastControlX, 2*curY-lastControlY
+				}
+				c.CubicTo(x1, y1, x2, y2, x, y)
+				lastControlX, lastControlY, haveLastControl = x2, y2, true
+				curX, curY = x, y
+
+			case 'Q':
+				x1, ok1 := p.scanNumber()
+				y1, ok2 := p.scanNumber()
+				x, ok3 := p.scanNumber()
+				y, ok4 := p.scanNumber()
+				if !(ok1 && ok2 && ok3 && ok4) {
+					break argsLoop
+				}
+				if relative {
+					x1, y1, x, y = x1+curX, y1+curY, x+curX, y+curY
+				}
+				c.QuadraticTo(x1, y1, x, y)
+				lastControlX, lastControlY, haveLastControl = x1, y1, true
+				curX, curY = x, y
+
+			case 'T':
+				x, ok1 := p.scanNumber()
+				y, ok2 := p.scanNumber()
+				if !(ok1 && ok2) {
+					break argsLoop
+				}
+				if relative {
+					x, y = x+curX, y+curY
+				}
+				x1, y1 := curX, curY
+				if haveLastControl && (lastCmd == 'Q' || lastCmd == 'T') {
+					x1, y1 = 2*curX-lastControlX, 2*curY-lastControlY
+				}
+				c.QuadraticTo(x1, y1, x, y)
+				lastControlX, lastControlY, haveLastControl = x1, y1, true
+				curX, curY = x, y
+
+			case 'A':
+				rx, ok1 := p.scanNumber()
+				ry, ok2 := p.scanNumber()
+				xrot, ok3 := p.scanNumber()
+				largeArc, ok4 := p.scanFlag()
+				sweep, ok5 := p.scanFlag()
+				x, ok6 := p.scanNumber()
+				y, ok7 := p.scanNumber()
+				if !(ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7) {
+					break argsLoop
+				}
+				if relative {
+					x += curX
+					y += curY
+				}
+				c.drawSVGArc(curX, curY, rx, ry, xrot, largeArc, sweep, x, y)
+				curX, curY = x, y
+				haveLastControl = false
+
+			case 'Z':
+				c.ClosePath()
+				curX, curY = startX, startY
+				haveLastControl = false
+				break argsLoop
+
+			default:
+				break argsLoop
+			}
+
+			first = false
+			lastCmd = cmd
+			if _, ok := p.peekCommand(); ok || p.pos >= len(p.s) {
+				break argsLoop
+			}
+		}
+	}
+
+	c.FillStroke()
+}
+
+// drawSVGArc converts an SVG elliptical arc from its endpoint
+// parameterization (the form used in path data) to center parameterization,
+// following the formulas in the SVG 1.1 spec (appendix F.6), then renders
+// it as a chain of cubic Béziers.
+func (c *Context) drawSVGArc(x1, y1, rx, ry, xAxisRotationDeg float64, largeArc, sweep bool, x2, y2 float64) {
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	if rx == 0 || ry == 0 {
+		c.LineTo(x2, y2)
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	phi := xAxisRotationDeg * math.Pi / 180
+
+	sinPhi, cosPhi := math.Sin(phi), math.Cos(phi)
+	dx2, dy2 := (x1-x2)/2, (y1-y2)/2
+	x1p := cosPhi*dx2 + sinPhi*dy2
+	y1p := -sinPhi*dx2 + cosPhi*dy2
+
+	lambda := (x1p*x1p)/(rx*rx) + (y1p*y1p)/(ry*ry)
+	if lambda > 1 {
+		scale := math.Sqrt(lambda)
+		rx, ry = rx*scale, ry*scale
+	}
+
+	sign := 1.0
+	if largeArc == sweep {
+		sign = -1
+	}
+	num := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	den := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	co := 0.0
+	if den != 0 && num/den > 0 {
+		co = sign * math.Sqrt(num/den)
+	}
+	cxp := co * rx * y1p / ry
+	cyp := co * -ry * x1p / rx
+
+	cx := cosPhi*cxp - sinPhi*cyp + (x1+x2)/2
+	cy := sinPhi*cxp + cosPhi*cyp + (y1+y2)/2
+
+	startAngle := svgVectorAngle(1, 0, (x1p-cxp)/rx, (y1p-cyp)/ry)
+	delta := svgVectorAngle((x1p-cxp)/rx, (y1p-cyp)/ry, (-x1p-cxp)/rx, (-y1p-cyp)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	if phi == 0 {
+		c.ArcTo(cx, cy, rx, ry, startAngle, delta)
+		return
+	}
+	c.arcToRotated(cx, cy, rx, ry, phi, startAngle, delta)
+}
+
+// arcToRotated renders an elliptical arc whose axes are rotated by phi
+// radians, chaining cubic Béziers the same way ArcTo does for an
+// unrotated ellipse, but with every point and tangent rotated by phi
+// around the center.
+func (c *Context) arcToRotated(cx, cy, rx, ry, phi, startAngle, sweepAngle float64) {
+	point := func(theta float64) (float64, float64) {
+		ex, ey := rx*math.Cos(theta), ry*math.Sin(theta)
+		return cx + ex*math.Cos(phi) - ey*math.Sin(phi), cy + ex*math.Sin(phi) + ey*math.Cos(phi)
+	}
+	tangent := func(theta float64) (float64, float64) {
+		ex, ey := -rx*math.Sin(theta), ry*math.Cos(theta)
+		return ex*math.Cos(phi) - ey*math.Sin(phi), ex*math.Sin(phi) + ey*math.Cos(phi)
+	}
+
+	sx, sy := point(startAngle)
+	c.LineTo(sx, sy)
+
+	remaining := sweepAngle
+	angle := startAngle
+	const maxSweep = math.Pi / 2
+	for remaining != 0 {
+		step := maxSweep
+		if step > math.Abs(remaining) {
+			step = math.Abs(remaining)
+		}
+		if remaining < 0 {
+			step = -step
+		}
+
+		p0x, p0y := point(angle)
+		p3x, p3y := point(angle + step)
+		k := 4.0 / 3.0 * math.Tan(step/4)
+
+		t0x, t0y := tangent(angle)
+		t3x, t3y := tangent(angle + step)
+
+		c.CubicTo(p0x+k*t0x, p0y+k*t0y, p3x-k*t3x, p3y-k*t3y, p3x, p3y)
+
+		angle += step
+		remaining -= step
+	}
+}
+
+// svgVectorAngle returns the signed angle in radians from vector (ux, uy)
+// to vector (vx, vy), as used by the SVG endpoint-to-center arc formulas.
+func svgVectorAngle(ux, uy, vx, vy float64) float64 {
+	dot := ux*vx + uy*vy
+	lenProduct := math.Hypot(ux, uy) * math.Hypot(vx, vy)
+	cosAngle := dot / lenProduct
+	if cosAngle > 1 {
+		cosAngle = 1
+	} else if cosAngle < -1 {
+		cosAngle = -1
+	}
+	angle := math.Acos(cosAngle)
+	if ux*vy-uy*vx < 0 {
+		angle = -angle
+	}
+	return angle
+}
+
+// upperSVGCommand returns the uppercase (absolute) form of an SVG path
+// command letter.
+func upperSVGCommand(cmd byte) byte {
+	if cmd >= 'a' && cmd <= 'z' {
+		return cmd - ('a' - 'A')
+	}
+	return cmd
+}
+
+// svgScanner tokenizes an SVG path data string into command letters and
+// the numbers or flags that follow them, allowing commas or whitespace as
+// separators and no separator at all between adjacent numbers.
+type svgScanner struct {
+	s   string
+	pos int
+}
+
+// skipSeparators advances past any run of whitespace and commas.
+func (p *svgScanner) skipSeparators() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peekCommand reports the next command letter without consuming it.
+func (p *svgScanner) peekCommand() (byte, bool) {
+	p.skipSeparators()
+	if p.pos >= len(p.s) {
+		return 0, false
+	}
+	c := p.s[p.pos]
+	if isSVGCommand(c) {
+		return c, true
+	}
+	return 0, false
+}
+
+// next consumes and returns the next byte.
+func (p *svgScanner) next() byte {
+	c := p.s[p.pos]
+	p.pos++
+	return c
+}
+
+// scanNumber parses a number (with optional sign, fraction and exponent)
+// at the current position, skipping any leading separators. It reports
+// false, leaving the position unchanged, if no number is there.
+func (p *svgScanner) scanNumber() (float64, bool) {
+	p.skipSeparators()
+	start := p.pos
+	n := len(p.s)
+
+	if p.pos < n && (p.s[p.pos] == '+' || p.s[p.pos] == '-') {
+		p.pos++
+	}
+	hasDigits := false
+	for p.pos < n && isSVGDigit(p.s[p.pos]) {
+		p.pos++
+		hasDigits = true
+	}
+	if p.pos < n && p.s[p.pos] == '.' {
+		p.pos++
+		for p.pos < n && isSVGDigit(p.s[p.pos]) {
+			p.pos++
+			hasDigits = true
+		}
+	}
+	if !hasDigits {
+		p.pos = start
+		return 0, false
+	}
+	if p.pos < n && (p.s[p.pos] == 'e' || p.s[p.pos] == 'E') {
+		save := p.pos
+		p.pos++
+		if p.pos < n && (p.s[p.pos] == '+' || p.s[p.pos] == '-') {
+			p.pos++
+		}
+		expDigits := false
+		for p.pos < n && isSVGDigit(p.s[p.pos]) {
+			p.pos++
+			expDigits = true
+		}
+		if !expDigits {
+			p.pos = save
+		}
+	}
+
+	val, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return 0, false
+	}
+	return val, true
+}
+
+// scanFlag parses a single SVG arc flag, which is always exactly one '0'
+// or '1' character - unlike scanNumber, it must not consume any digits
+// that follow, since flags are often written with no separator between
+// them (e.g. in "A1 1 0 1110 20" the run "1110" holds both flags and the
+// x coordinate 10).
+func (p *svgScanner) scanFlag() (bool, bool) {
+	p.skipSeparators()
+	if p.pos >= len(p.s) {
+		return false, false
+	}
+	c := p.s[p.pos]
+	if c == '0' || c == '1' {
+		p.pos++
+		return c == '1', true
+	}
+	return false, false
+}
+
+func isSVGDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isSVGCommand(c byte) bool {
+	switch c {
+	case 'M', 'm', 'L', 'l', 'H', 'h', 'V', 'v', 'C', 'c', 'S', 's', 'Q', 'q', 'T', 't', 'A', 'a', 'Z', 'z':
+		return true
+	}
+	return false
+}
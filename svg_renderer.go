@@ -0,0 +1,246 @@
+package draw
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+// svgRenderer is the Renderer behind NewSVGContext. Rather than
+// rasterizing into an image.RGBA, it accumulates the path data built up
+// by MoveTo/LineTo/QuadraticTo/CubicTo/ArcTo as an SVG "d" attribute, and
+// writes a <path> element for each Fill or Stroke and a <text> element
+// for each Text. MoveTo/LineTo/Curve receive their coordinates already
+// transformed by Context (see MoveTo's doc comment), so paths need no
+// further transform handling here; Text, however, is given its untransformed
+// x,y directly, so it keeps a reference to ctx to honor the current
+// transform with an SVG transform="matrix(...)" attribute.
+type svgRenderer struct {
+	ctx           *Context
+	w             io.Writer
+	width, height int
+
+	path    strings.Builder // accumulated "d" attribute of the current path
+	flushed bool            // true once Fill or Stroke has read path; cleared on the next MoveTo
+
+	penColor  color.Color
+	fillColor color.Color
+	fontFace  font.Face
+
+	saved []svgRendererState
+}
+
+// svgRendererState is the style snapshot pushed and popped by Save/Restore.
+type svgRendererState struct {
+	penColor, fillColor color.Color
+	fontFace            font.Face
+}
+
+// newSVGRenderer creates a Renderer that writes an SVG document of the
+// given pixel dimensions to w, starting with its opening <svg> tag.
+func newSVGRenderer(ctx *Context, w io.Writer, width, height int) *svgRenderer {
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, width, height)
+	return &svgRenderer{
+		ctx:       ctx,
+		w:         w,
+		width:     width,
+		height:    height,
+		penColor:  color.Black,
+		fillColor: color.Transparent,
+	}
+}
+
+// Finalize writes the closing </svg> tag.
+func (r *svgRenderer) Finalize() error {
+	_, err := io.WriteString(r.w, "</svg>\n")
+	return err
+}
+
+func (r *svgRenderer) MoveTo(x, y float64) {
+	if r.flushed {
+		r.path.Reset()
+		r.flushed = false
+	}
+	fmt.Fprintf(&r.path, "M%s,%s ", formatSVGNumber(x), formatSVGNumber(y))
+}
+
+func (r *svgRenderer) LineTo(x, y float64) {
+	fmt.Fprintf(&r.path, "L%s,%s ", formatSVGNumber(x), formatSVGNumber(y))
+}
+
+func (r *svgRenderer) Curve(cx1, cy1, cx2, cy2, x, y float64) {
+	fmt.Fprintf(&r.path, "C%s,%s %s,%s %s,%s ",
+		formatSVGNumber(cx1), formatSVGNumber(cy1),
+		formatSVGNumber(cx2), formatSVGNumber(cy2),
+		formatSVGNumber(x), formatSVGNumber(y))
+}
+
+func (r *svgRenderer) Close() {
+	r.path.WriteString("Z ")
+}
+
+// Fill writes a <path> element filling the accumulated path data with the
+// renderer's fill color and the given winding rule. It does not clear the
+// path data, so a subsequent Stroke call in the same FillStroke can still
+// see it; the next MoveTo clears it instead.
+func (r *svgRenderer) Fill(rule WindingRule) {
+	if r.path.Len() == 0 || r.fillColor == nil || r.fillColor == color.Transparent {
+		r.flushed = true
+		return
+	}
+	fillRule := "evenodd"
+	if rule == NonZero {
+		fillRule = "nonzero"
+	}
+	fmt.Fprintf(r.w, "<path d=\"%s\" fill=\"%s\"%s fill-rule=\"%s\" stroke=\"none\"/>\n",
+		strings.TrimSpace(r.path.String()), svgColor(r.fillColor), svgOpacityAttr("fill", r.fillColor), fillRule)
+	r.flushed = true
+}
+
+// Stroke writes a <path> element outlining the accumulated path data with
+// the renderer's pen color and the given stroke style.
+func (r *svgRenderer) Stroke(style StrokeStyle) {
+	if r.path.Len() == 0 || r.penColor == nil || r.penColor == color.Transparent {
+		r.flushed = true
+		return
+	}
+	width := style.Width
+	if width <= 0 {
+		width = 1
+	}
+	fmt.Fprintf(r.w, "<path d=\"%s\" fill=\"none\" stroke=\"%s\"%s stroke-width=\"%s\" stroke-linecap=\"%s\" stroke-linejoin=\"%s\"%s/>\n",
+		strings.TrimSpace(r.path.String()), svgColor(r.penColor), svgOpacityAttr("stroke", r.penColor),
+		formatSVGNumber(width), svgLineCap(style.Cap), svgLineJoin(style.Join), svgDashArray(style.Dash))
+	r.flushed = true
+}
+
+// Text writes a <text> element at x,y. font.Face exposes no family name or
+// point size, so font-size is approximated from the face's line height and
+// font-family is left as a generic fallback. x,y arrives untransformed (see
+// the svgRenderer doc comment), so if the context's current transform is
+// not the identity, it is emitted as an SVG transform="matrix(...)"
+// attribute - SVG's matrix(a,b,c,d,e,f) applies to a point with exactly the
+// same a*x+c*y+e, b*x+d*y+f formula as Matrix.Transform, so the fields can
+// be passed straight through.
+func (r *svgRenderer) Text(x, y float64, text string, face font.Face, clr color.Color) {
+	size := fixedToFloat(face.Metrics().Height)
+	fmt.Fprintf(r.w, "<text x=\"%s\" y=\"%s\" font-family=\"sans-serif\" font-size=\"%s\" fill=\"%s\"%s>%s</text>\n",
+		formatSVGNumber(x), formatSVGNumber(y), formatSVGNumber(size), svgColor(clr), svgTransformAttr(r.ctx.transform), escapeSVGText(text))
+}
+
+// svgTransformAttr returns a " transform=\"matrix(...)\"" attribute for m,
+// or an empty string if m is the identity matrix.
+func svgTransformAttr(m Matrix) string {
+	if m == identityMatrix() {
+		return ""
+	}
+	return fmt.Sprintf(" transform=\"matrix(%s,%s,%s,%s,%s,%s)\"",
+		formatSVGNumber(m.a), formatSVGNumber(m.b), formatSVGNumber(m.c),
+		formatSVGNumber(m.d), formatSVGNumber(m.e), formatSVGNumber(m.f))
+}
+
+func (r *svgRenderer) SetPen(clr color.Color)     { r.penColor = clr }
+func (r *svgRenderer) SetFill(clr color.Color)    { r.fillColor = clr }
+func (r *svgRenderer) SetFontFace(face font.Face) { r.fontFace = face }
+
+func (r *svgRenderer) Save() {
+	r.saved = append(r.saved, svgRendererState{penColor: r.penColor, fillColor: r.fillColor, fontFace: r.fontFace})
+}
+
+func (r *svgRenderer) Restore() {
+	n := len(r.saved)
+	if n == 0 {
+		return
+	}
+	state := r.saved[n-1]
+	r.saved = r.saved[:n-1]
+	r.penColor, r.fillColor, r.fontFace = state.penColor, state.fillColor, state.fontFace
+}
+
+// formatSVGNumber formats a coordinate or length rounded to 4 decimal
+// places, e.g. "12" rather than "12.000000" and "18.3333" rather than
+// "18.333333333333332".
+func formatSVGNumber(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 4, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// svgColor converts a color.Color to an SVG "#rrggbb" value, or "none" for
+// a nil color, color.Transparent, or any other fully transparent color.
+func svgColor(clr color.Color) string {
+	if clr == nil {
+		return "none"
+	}
+	r, g, b, a := clr.RGBA()
+	if a == 0 {
+		return "none"
+	}
+	// RGBA returns alpha-premultiplied 16-bit channels; undo the
+	// premultiplication before truncating down to 8 bits per channel.
+	r = r * 0xffff / a
+	g = g * 0xffff / a
+	b = b * 0xffff / a
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// svgOpacityAttr returns a " fill-opacity"/"stroke-opacity" attribute for
+// clr if it is translucent, or an empty string for an opaque color.
+func svgOpacityAttr(attr string, clr color.Color) string {
+	_, _, _, a := clr.RGBA()
+	if a == 0xffff {
+		return ""
+	}
+	return fmt.Sprintf(" %s-opacity=\"%s\"", attr, formatSVGNumber(float64(a)/0xffff))
+}
+
+func svgLineCap(cap LineCap) string {
+	switch cap {
+	case CapRound:
+		return "round"
+	case CapSquare:
+		return "square"
+	default:
+		return "butt"
+	}
+}
+
+func svgLineJoin(join LineJoin) string {
+	switch join {
+	case JoinRound:
+		return "round"
+	case JoinBevel:
+		return "bevel"
+	default:
+		return "miter"
+	}
+}
+
+// svgDashArray returns a " stroke-dasharray" attribute for dash, plus a
+// " stroke-dashoffset" attribute if dash.Phase is non-zero, or an empty
+// string if dash has no pattern.
+func svgDashArray(dash Dash) string {
+	if len(dash.Pattern) == 0 {
+		return ""
+	}
+	parts := make([]string, len(dash.Pattern))
+	for i, d := range dash.Pattern {
+		parts[i] = formatSVGNumber(d)
+	}
+	attr := fmt.Sprintf(" stroke-dasharray=\"%s\"", strings.Join(parts, ","))
+	if dash.Phase != 0 {
+		attr += fmt.Sprintf(" stroke-dashoffset=\"%s\"", formatSVGNumber(dash.Phase))
+	}
+	return attr
+}
+
+var svgTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func escapeSVGText(s string) string {
+	return svgTextEscaper.Replace(s)
+}
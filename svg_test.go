@@ -0,0 +1,88 @@
+package draw
+
+import "testing"
+
+// TestSVGScannerNumbers verifies scanNumber against the single-number forms
+// SVG path data allows: an optional sign, a fraction with or without a
+// leading digit, and an exponent. Each string is consumed whole.
+func TestSVGScannerNumbers(t *testing.T) {
+	tests := []struct {
+		s    string
+		want float64
+	}{
+		{"1", 1},
+		{"-1.5", -1.5},
+		{"+2.25", 2.25},
+		{".5", 0.5},
+		{"1e2", 100},
+		{"1.5e-1", 0.15},
+	}
+	for _, tt := range tests {
+		p := &svgScanner{s: tt.s}
+		got, ok := p.scanNumber()
+		if !ok {
+			t.Errorf("scanNumber(%q) reported no number found", tt.s)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("scanNumber(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+		if p.pos != len(tt.s) {
+			t.Errorf("scanNumber(%q) left pos at %d, want %d (whole string consumed)", tt.s, p.pos, len(tt.s))
+		}
+	}
+}
+
+// TestSVGScannerAdjacentNumbers verifies that two numbers written with no
+// separator between them (e.g. as real-world path data minifiers emit) are
+// parsed as two separate numbers, not one.
+func TestSVGScannerAdjacentNumbers(t *testing.T) {
+	p := &svgScanner{s: "1.5-2.5"}
+	a, ok := p.scanNumber()
+	if !ok || a != 1.5 {
+		t.Fatalf("first scanNumber() = %v, %v, want 1.5, true", a, ok)
+	}
+	b, ok := p.scanNumber()
+	if !ok || b != -2.5 {
+		t.Fatalf("second scanNumber() = %v, %v, want -2.5, true", b, ok)
+	}
+}
+
+// TestSVGScannerFlagsDoNotConsumeDigits verifies scanFlag reads exactly one
+// '0' or '1' character even when immediately followed by another digit, as
+// in compact arc commands like "A1 1 0 1110 20", where the two flags run
+// straight into each other and into the x coordinate.
+func TestSVGScannerFlagsDoNotConsumeDigits(t *testing.T) {
+	p := &svgScanner{s: "11"}
+	first, ok := p.scanFlag()
+	if !ok || !first {
+		t.Fatalf("first scanFlag() = %v, %v, want true, true", first, ok)
+	}
+	second, ok := p.scanFlag()
+	if !ok || !second {
+		t.Fatalf("second scanFlag() = %v, %v, want true, true", second, ok)
+	}
+	if p.pos != 2 {
+		t.Errorf("pos after two flags = %d, want 2", p.pos)
+	}
+}
+
+// TestDrawSVGPathArcReachesEndpoint verifies that an elliptical arc command
+// parsed from path data ends at its declared endpoint, regardless of the
+// large-arc and sweep flags, exercising the endpoint-to-center conversion in
+// drawSVGArc for all four flag combinations.
+func TestDrawSVGPathArcReachesEndpoint(t *testing.T) {
+	for _, largeArc := range []string{"0", "1"} {
+		for _, sweep := range []string{"0", "1"} {
+			c := &Context{transform: identityMatrix()}
+			c.renderer = &rasterRenderer{ctx: c}
+			c.MoveTo(10, 0)
+			c.drawSVGArc(10, 0, 10, 10, 0, largeArc == "1", sweep == "1", 0, 10)
+
+			if !almostEqual(c.current.X, 0) || !almostEqual(c.current.Y, 10) {
+				t.Errorf("arc with largeArc=%s sweep=%s ended at (%v,%v), want (0,10)",
+					largeArc, sweep, c.current.X, c.current.Y)
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package draw
+
+import (
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Align is a horizontal or vertical text alignment, used by
+// DrawStringWrapped. AlignLeft, AlignCenter and AlignRight are horizontal
+// alignments; AlignTop, AlignMiddle, AlignBottom and AlignBaseline are
+// vertical alignments.
+type Align int
+
+// Supported alignments for DrawStringWrapped.
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+	AlignTop
+	AlignMiddle
+	AlignBottom
+	AlignBaseline
+)
+
+// MeasureString returns the width and height, in pixels, of a single line
+// of text in the context's current font face.
+func (c *Context) MeasureString(s string) (w, h float64) {
+	return measureLine(c.fontDrawer.Face, s), fixedToFloat(c.fontDrawer.Face.Metrics().Height)
+}
+
+// MeasureMultilineString returns the width and height, in pixels, that s
+// would occupy if drawn with DrawStringWrapped at the given line spacing
+// and wrapping width (see DrawStringWrapped for how maxWidth is applied).
+func (c *Context) MeasureMultilineString(s string, lineSpacing, maxWidth float64) (w, h float64) {
+	lines := c.wrapText(s, maxWidth)
+	lineHeight := fixedToFloat(c.fontDrawer.Face.Metrics().Height) * lineSpacing
+
+	var maxLineWidth float64
+	for _, line := range lines {
+		if lw := measureLine(c.fontDrawer.Face, line); lw > maxLineWidth {
+			maxLineWidth = lw
+		}
+	}
+	return maxLineWidth, lineHeight * float64(len(lines))
+}
+
+// DrawStringWrapped draws text wrapped to maxWidth (pass 0 for no
+// wrapping), with each line's block anchored at x,y according to hAlign
+// and vAlign, and successive lines spaced lineSpacing times the font's
+// line height apart.
+func (c *Context) DrawStringWrapped(text string, x, y, maxWidth float64, hAlign, vAlign Align, lineSpacing float64) {
+	lines := c.wrapText(text, maxWidth)
+	if len(lines) == 0 {
+		return
+	}
+
+	metrics := c.fontDrawer.Face.Metrics()
+	lineHeight := fixedToFloat(metrics.Height) * lineSpacing
+	ascent := fixedToFloat(metrics.Ascent)
+	blockHeight := lineHeight * float64(len(lines))
+
+	var startY float64
+	switch vAlign {
+	case AlignMiddle:
+		startY = y - blockHeight/2 + ascent
+	case AlignBottom:
+		startY = y - blockHeight + ascent
+	case AlignBaseline:
+		startY = y - blockHeight + lineHeight
+	default: // AlignTop
+		startY = y + ascent
+	}
+
+	for i, line := range lines {
+		lineWidth := measureLine(c.fontDrawer.Face, line)
+		var lineX float64
+		switch hAlign {
+		case AlignCenter:
+			lineX = x - lineWidth/2
+		case AlignRight:
+			lineX = x - lineWidth
+		default: // AlignLeft
+			lineX = x
+		}
+		c.TextF(lineX, startY+float64(i)*lineHeight, line)
+	}
+}
+
+// wrapText splits s into display lines: first on existing newlines, then
+// by greedily wrapping each paragraph to maxWidth (0 means don't wrap).
+func (c *Context) wrapText(s string, maxWidth float64) []string {
+	paragraphs := strings.Split(s, "\n")
+	lines := make([]string, 0, len(paragraphs))
+	for _, para := range paragraphs {
+		lines = append(lines, wrapParagraph(c.fontDrawer.Face, para, maxWidth)...)
+	}
+	return lines
+}
+
+// wrapParagraph greedily accumulates space-separated words from text into
+// lines no wider than maxWidth, measured with face's glyph advances and
+// kerning. A single word wider than maxWidth is kept on its own line
+// rather than split. maxWidth <= 0 disables wrapping.
+func wrapParagraph(face font.Face, text string, maxWidth float64) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	lines := make([]string, 0, 1)
+	line := words[0]
+	for _, word := range words[1:] {
+		candidate := line + " " + word
+		if maxWidth > 0 && measureLine(face, candidate) > maxWidth {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line = candidate
+	}
+	return append(lines, line)
+}
+
+// measureLine returns the width, in pixels, of a single line of text set
+// in face, summing each rune's glyph advance plus the kerning between it
+// and the rune before it.
+func measureLine(face font.Face, s string) float64 {
+	var width fixed.Int26_6
+	var prev rune
+	hasPrev := false
+	for _, r := range s {
+		advance, ok := face.GlyphAdvance(r)
+		if !ok {
+			continue
+		}
+		if hasPrev {
+			width += face.Kern(prev, r)
+		}
+		width += advance
+		prev = r
+		hasPrev = true
+	}
+	return fixedToFloat(width)
+}
+
+// fixedToFloat converts a 26.6 fixed-point value to a float64.
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
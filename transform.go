@@ -0,0 +1,113 @@
+package draw
+
+import "math"
+
+// PointF is a point with floating point coordinates, used by the Float64
+// counterparts of Context's integer-coordinate drawing methods.
+type PointF struct {
+	X, Y float64
+}
+
+// Matrix is a 2D affine transform, stored as the top two rows of the usual
+// 3x3 homogeneous matrix:
+//
+//	| a  c  e |   | x |
+//	| b  d  f | * | y |
+//	| 0  0  1 |   | 1 |
+type Matrix struct {
+	a, b, c, d, e, f float64
+}
+
+// identityMatrix returns the affine transform that leaves points unchanged.
+func identityMatrix() Matrix {
+	return Matrix{a: 1, d: 1}
+}
+
+// Transform applies the matrix to the point x,y and returns the result.
+func (m Matrix) Transform(x, y float64) (float64, float64) {
+	return m.a*x + m.c*y + m.e, m.b*x + m.d*y + m.f
+}
+
+// Mul composes two matrices so that m.Mul(other).Transform(p) is equivalent
+// to m.Transform(other.Transform(p)) - other is applied first.
+func (m Matrix) Mul(other Matrix) Matrix {
+	return Matrix{
+		a: m.a*other.a + m.c*other.b,
+		b: m.b*other.a + m.d*other.b,
+		c: m.a*other.c + m.c*other.d,
+		d: m.b*other.c + m.d*other.d,
+		e: m.a*other.e + m.c*other.f + m.e,
+		f: m.b*other.e + m.d*other.f + m.f,
+	}
+}
+
+// Inverse returns the matrix that undoes m's transform, or the identity
+// matrix if m is singular (has no inverse). Used to map a device-space
+// pixel back to the user-space point that produced it, e.g. when warping a
+// glyph mask to follow Rotate/Shear in rasterRenderer.Text.
+func (m Matrix) Inverse() Matrix {
+	det := m.a*m.d - m.b*m.c
+	if det == 0 {
+		return identityMatrix()
+	}
+	ia := m.d / det
+	ib := -m.b / det
+	ic := -m.c / det
+	id := m.a / det
+	return Matrix{
+		a: ia,
+		b: ib,
+		c: ic,
+		d: id,
+		e: -(ia*m.e + ic*m.f),
+		f: -(ib*m.e + id*m.f),
+	}
+}
+
+// Push saves the current transform so it can later be restored with Pop,
+// and asks the renderer to save its own style state (see Renderer.Save).
+func (c *Context) Push() {
+	c.transformStack = append(c.transformStack, c.transform)
+	c.renderer.Save()
+}
+
+// Pop restores the transform most recently saved with Push, and the
+// renderer style state saved alongside it (see Renderer.Restore). It is a
+// no-op if there is nothing left to restore.
+func (c *Context) Pop() {
+	n := len(c.transformStack)
+	if n == 0 {
+		return
+	}
+	c.transform = c.transformStack[n-1]
+	c.transformStack = c.transformStack[:n-1]
+	c.renderer.Restore()
+}
+
+// Identity resets the current transform to the identity matrix, without
+// touching the Push/Pop stack.
+func (c *Context) Identity() {
+	c.transform = identityMatrix()
+}
+
+// Translate moves the origin of the current coordinate system by dx,dy.
+func (c *Context) Translate(dx, dy float64) {
+	c.transform = c.transform.Mul(Matrix{a: 1, d: 1, e: dx, f: dy})
+}
+
+// Scale scales the current coordinate system by sx,sy.
+func (c *Context) Scale(sx, sy float64) {
+	c.transform = c.transform.Mul(Matrix{a: sx, d: sy})
+}
+
+// Rotate rotates the current coordinate system clockwise by angle radians
+// (clockwise because Y grows downward in image space).
+func (c *Context) Rotate(angle float64) {
+	sin, cos := math.Sin(angle), math.Cos(angle)
+	c.transform = c.transform.Mul(Matrix{a: cos, b: sin, c: -sin, d: cos})
+}
+
+// Shear skews the current coordinate system by sx,sy.
+func (c *Context) Shear(sx, sy float64) {
+	c.transform = c.transform.Mul(Matrix{a: 1, b: sy, c: sx, d: 1})
+}
@@ -0,0 +1,63 @@
+package draw
+
+import (
+	"math"
+	"testing"
+)
+
+// almostEqual reports whether a and b differ by no more than a small
+// tolerance, to absorb floating point error in matrix composition.
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestMatrixMulAppliesOtherFirst verifies the documented order of Mul: for
+// m.Mul(other), other is applied to the point first, then m.
+func TestMatrixMulAppliesOtherFirst(t *testing.T) {
+	translate := Matrix{a: 1, d: 1, e: 10, f: 0}
+	scale := Matrix{a: 2, d: 2}
+
+	combined := translate.Mul(scale)
+	x, y := combined.Transform(1, 1)
+	// scale first: (1,1) -> (2,2); then translate: (2,2) -> (12,2).
+	if !almostEqual(x, 12) || !almostEqual(y, 2) {
+		t.Errorf("translate.Mul(scale).Transform(1,1) = (%v,%v), want (12,2)", x, y)
+	}
+
+	reversed := scale.Mul(translate)
+	x, y = reversed.Transform(1, 1)
+	// translate first: (1,1) -> (11,1); then scale: (11,1) -> (22,2).
+	if !almostEqual(x, 22) || !almostEqual(y, 2) {
+		t.Errorf("scale.Mul(translate).Transform(1,1) = (%v,%v), want (22,2)", x, y)
+	}
+}
+
+// TestMatrixInverseUndoesTransform verifies that applying a matrix and then
+// its Inverse returns the original point, for a matrix combining rotation,
+// scale and translation.
+func TestMatrixInverseUndoesTransform(t *testing.T) {
+	m := identityMatrix()
+	m = m.Mul(Matrix{a: 1, d: 1, e: 5, f: -3})
+	sin, cos := math.Sin(0.7), math.Cos(0.7)
+	m = m.Mul(Matrix{a: cos, b: sin, c: -sin, d: cos})
+	m = m.Mul(Matrix{a: 2, d: 3})
+
+	inverse := m.Inverse()
+	px, py := 4.0, 7.0
+	dx, dy := m.Transform(px, py)
+	ux, uy := inverse.Transform(dx, dy)
+
+	if !almostEqual(ux, px) || !almostEqual(uy, py) {
+		t.Errorf("m.Inverse().Transform(m.Transform(%v,%v)) = (%v,%v), want (%v,%v)", px, py, ux, uy, px, py)
+	}
+}
+
+// TestMatrixInverseSingularReturnsIdentity verifies that Inverse falls back
+// to the identity matrix for a singular matrix (here, one that collapses
+// every point onto a line), rather than dividing by a zero determinant.
+func TestMatrixInverseSingularReturnsIdentity(t *testing.T) {
+	singular := Matrix{a: 1, b: 1, c: 2, d: 2}
+	if got := singular.Inverse(); got != identityMatrix() {
+		t.Errorf("singular matrix's Inverse() = %+v, want the identity matrix", got)
+	}
+}